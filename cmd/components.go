@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/spf13/viper"
+
+	drift "github.com/ha36d/drift-checker/internal/drift"
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// componentSpec is the configuration of one entry of Config.Components:
+//
+//	components:
+//	  - network:
+//	      path: ./stacks/network
+//	      runner: tofu            # overrides --runner / the top-level runner key
+//	      workspace: prod
+//	      var_files: [prod.tfvars]  # appended to --var-file / plan.var_files
+//	      vars: {region: eu-west-1}  # appended to --var / plan.vars
+//	      targets: [module.vpc]      # replaces --target / plan.targets
+//	      lock: false
+//	      lock_timeout: 5m
+//	      parallelism: 20
+//	      init: true
+//	      backend_config: [prod.s3.tfbackend]  # replaces --backend-config / plan.backend_config
+//	      env:
+//	        AWS_PROFILE: prod
+type componentSpec struct {
+	Path          string
+	Runner        string
+	Workspace     string
+	VarFiles      []string
+	Vars          map[string]string
+	Targets       []string
+	Lock          *bool
+	LockTimeout   *time.Duration
+	Parallelism   *int
+	Init          *bool
+	BackendConfig []string
+	Env           map[string]string
+}
+
+// parseComponents turns the raw component list of the config file into scan
+// components. Relative paths are resolved against the config file directory.
+func parseComponents(raw []map[string]map[string]any, base drift.Options) ([]drift.Component, error) {
+	dir := "."
+	if f := viper.ConfigFileUsed(); f != "" {
+		dir = filepath.Dir(f)
+	}
+
+	var comps []drift.Component
+	seen := map[string]bool{}
+	for i, entry := range raw {
+		if len(entry) != 1 {
+			return nil, fmt.Errorf("components[%d]: expected a single component name, got %d keys", i, len(entry))
+		}
+		for name, values := range entry {
+			if seen[name] {
+				return nil, fmt.Errorf("components[%d]: duplicate component %q", i, name)
+			}
+			seen[name] = true
+
+			spec, err := decodeComponent(values)
+			if err != nil {
+				return nil, fmt.Errorf("component %s: %w", name, err)
+			}
+			runner, err := plan.ParseRunner(spec.Runner)
+			if err != nil {
+				return nil, fmt.Errorf("component %s: %w", name, err)
+			}
+			if spec.Runner == "" {
+				runner = base.Runner
+			}
+
+			opts := base
+			opts.Path = spec.Path
+			if opts.Path == "" {
+				opts.Path = name
+			}
+			if !filepath.IsAbs(opts.Path) {
+				opts.Path = filepath.Join(dir, opts.Path)
+			}
+			opts.Runner = runner
+			opts.Workspace = spec.Workspace
+			opts.VarFiles = slices.Concat(base.VarFiles, spec.VarFiles)
+			opts.Vars = slices.Concat(base.Vars, keyValues(spec.Vars))
+			if spec.Targets != nil {
+				opts.Targets = spec.Targets
+			}
+			if spec.Lock != nil {
+				opts.NoLock = !*spec.Lock
+			}
+			if spec.LockTimeout != nil {
+				opts.LockTimeout = *spec.LockTimeout
+			}
+			if spec.Parallelism != nil {
+				opts.Parallelism = *spec.Parallelism
+			}
+			if spec.BackendConfig != nil {
+				opts.BackendConfig = spec.BackendConfig
+				opts.Init = true
+			}
+			if spec.Init != nil {
+				opts.Init = *spec.Init
+			}
+			opts.Env = keyValues(spec.Env)
+			comps = append(comps, drift.Component{Name: name, Options: opts})
+		}
+	}
+	return comps, nil
+}
+
+func decodeComponent(values map[string]any) (componentSpec, error) {
+	var spec componentSpec
+	for k, v := range values {
+		var err error
+		switch k {
+		case "path":
+			spec.Path, err = stringValue(k, v)
+		case "runner":
+			spec.Runner, err = stringValue(k, v)
+		case "workspace":
+			spec.Workspace, err = stringValue(k, v)
+		case "var_files":
+			spec.VarFiles, err = stringList(k, v)
+		case "vars":
+			spec.Vars, err = stringMap(k, v)
+		case "targets":
+			spec.Targets, err = stringList(k, v)
+		case "lock":
+			spec.Lock, err = boolValue(k, v)
+		case "init":
+			spec.Init, err = boolValue(k, v)
+		case "backend_config":
+			spec.BackendConfig, err = stringList(k, v)
+		case "lock_timeout":
+			var d time.Duration
+			d, err = durationValue(k, v)
+			spec.LockTimeout = &d
+		case "parallelism":
+			n, ok := v.(int)
+			if !ok || n < 0 {
+				err = fmt.Errorf("%s: expected a non-negative integer, got %v", k, v)
+			}
+			spec.Parallelism = &n
+		case "env":
+			spec.Env, err = stringMap(k, v)
+		default:
+			err = fmt.Errorf("unknown key %q", k)
+		}
+		if err != nil {
+			return componentSpec{}, err
+		}
+	}
+	return spec, nil
+}
+
+func stringValue(key string, v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: expected a string, got %T", key, v)
+	}
+	return s, nil
+}
+
+func boolValue(key string, v any) (*bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a boolean, got %T", key, v)
+	}
+	return &b, nil
+}
+
+func durationValue(key string, v any) (time.Duration, error) {
+	s, err := stringValue(key, v)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s: expected a duration such as 30s or 5m, got %q", key, s)
+	}
+	return d, nil
+}
+
+func stringList(key string, v any) ([]string, error) {
+	switch l := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{l}, nil
+	case []any:
+		out := make([]string, 0, len(l))
+		for _, e := range l {
+			s, err := stringValue(key, e)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	case []string:
+		return l, nil
+	}
+	return nil, fmt.Errorf("%s: expected a list of strings, got %T", key, v)
+}
+
+func stringMap(key string, v any) (map[string]string, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		if v == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: expected a map, got %T", key, v)
+	}
+	out := make(map[string]string, len(m))
+	for k, e := range m {
+		out[k] = fmt.Sprint(e)
+	}
+	return out, nil
+}
+
+// keyValues converts m into KEY=VALUE entries sorted by key.
+func keyValues(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, k+"="+m[k])
+	}
+	return out
+}
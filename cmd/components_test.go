@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ha36d/drift-checker/internal/drift"
+	"github.com/ha36d/drift-checker/internal/plan"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestParseComponents(t *testing.T) {
+	raw := []map[string]map[string]any{
+		{"network": {
+			"path":      "stacks/network",
+			"runner":    "terraform",
+			"workspace": "prod",
+			"var_files": []any{"prod.tfvars"},
+			"env":       map[string]any{"TF_LOG": "warn", "AWS_PROFILE": "prod"},
+		}},
+		{"dns": nil},
+	}
+	comps, err := parseComponents(raw, drift.Options{Format: "json"})
+	if err != nil {
+		t.Fatalf("parseComponents error: %v", err)
+	}
+	if len(comps) != 2 || comps[0].Name != "network" || comps[1].Name != "dns" {
+		t.Fatalf("unexpected components: %+v", comps)
+	}
+
+	o := comps[0].Options
+	if o.Path != filepath.Join(".", "stacks/network") || o.Runner != plan.RunnerTerraform || o.Workspace != "prod" || o.Format != "json" {
+		t.Fatalf("unexpected options: %+v", o)
+	}
+	if !reflect.DeepEqual(o.VarFiles, []string{"prod.tfvars"}) {
+		t.Fatalf("unexpected var files: %v", o.VarFiles)
+	}
+	if !reflect.DeepEqual(o.Env, []string{"AWS_PROFILE=prod", "TF_LOG=warn"}) {
+		t.Fatalf("unexpected env: %v", o.Env)
+	}
+	if comps[1].Options.Path != "dns" || comps[1].Options.Runner != "" {
+		t.Fatalf("unexpected defaults: %+v", comps[1].Options)
+	}
+
+	for name, bad := range map[string][]map[string]map[string]any{
+		"unknown key":    {{"a": {"paht": "x"}}},
+		"bad runner":     {{"a": {"runner": "pulumi"}}},
+		"duplicate name": {{"a": nil}, {"a": nil}},
+		"two names":      {{"a": nil, "b": nil}},
+	} {
+		if _, err := parseComponents(bad, drift.Options{}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// loadConfigFile loads content as the config file, as initConfig does.
+func loadConfigFile(t *testing.T, content string) {
+	t.Helper()
+	f := filepath.Join(t.TempDir(), ".config.yaml")
+	if err := os.WriteFile(f, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		viper.Reset()
+		config = Config{}
+	})
+	viper.SetConfigFile(f)
+	if err := loadAndValidateConfig(); err != nil {
+		t.Fatalf("loadAndValidateConfig error: %v", err)
+	}
+}
+
+func TestParseComponents_ConfigFileKeepsCase(t *testing.T) {
+	loadConfigFile(t, `components:
+  - Network:
+      path: stacks/network
+      env:
+        AWS_PROFILE: prod
+`)
+	comps, err := parseComponents(config.Components, drift.Options{})
+	if err != nil {
+		t.Fatalf("parseComponents error: %v", err)
+	}
+	if len(comps) != 1 || comps[0].Name != "Network" {
+		t.Fatalf("component name not preserved: %+v", comps)
+	}
+	if !reflect.DeepEqual(comps[0].Options.Env, []string{"AWS_PROFILE=prod"}) {
+		t.Fatalf("env keys not preserved: %v", comps[0].Options.Env)
+	}
+}
+
+func TestPlanVars_ConfigFileKeepsCase(t *testing.T) {
+	loadConfigFile(t, `plan:
+  vars:
+    imageTag: v1
+    replicaCount: 3
+components:
+  - api:
+      vars:
+        dbInstanceClass: db.t3.large
+`)
+	cmd := &cobra.Command{}
+	addPlanFlags(cmd)
+	var o drift.Options
+	if err := applyPlanOptions(cmd, &o, config.Plan); err != nil {
+		t.Fatalf("applyPlanOptions error: %v", err)
+	}
+	if !reflect.DeepEqual(o.Vars, []string{"imageTag=v1", "replicaCount=3"}) {
+		t.Fatalf("plan.vars names not preserved: %v", o.Vars)
+	}
+	comps, err := parseComponents(config.Components, o)
+	if err != nil {
+		t.Fatalf("parseComponents error: %v", err)
+	}
+	if want := []string{"imageTag=v1", "replicaCount=3", "dbInstanceClass=db.t3.large"}; !reflect.DeepEqual(comps[0].Options.Vars, want) {
+		t.Fatalf("component vars names not preserved: %v, want %v", comps[0].Options.Vars, want)
+	}
+}
+
+func TestParseComponents_PlanOptions(t *testing.T) {
+	base := drift.Options{
+		VarFiles: []string{"common.tfvars"},
+		Vars:     []string{"env=prod"},
+		Targets:  []string{"module.all"},
+	}
+	raw := []map[string]map[string]any{
+		{"db": {
+			"var_files":      []any{"db.tfvars"},
+			"vars":           map[string]any{"size": 3},
+			"targets":        []any{"module.db"},
+			"lock":           false,
+			"lock_timeout":   "2m",
+			"parallelism":    5,
+			"backend_config": []any{"db.tfbackend"},
+		}},
+		{"web": nil},
+	}
+	comps, err := parseComponents(raw, base)
+	if err != nil {
+		t.Fatalf("parseComponents error: %v", err)
+	}
+
+	db := comps[0].Options
+	if !reflect.DeepEqual(db.VarFiles, []string{"common.tfvars", "db.tfvars"}) ||
+		!reflect.DeepEqual(db.Vars, []string{"env=prod", "size=3"}) ||
+		!reflect.DeepEqual(db.Targets, []string{"module.db"}) ||
+		!db.NoLock || db.LockTimeout != 2*time.Minute || db.Parallelism != 5 ||
+		!db.Init || !reflect.DeepEqual(db.BackendConfig, []string{"db.tfbackend"}) {
+		t.Fatalf("unexpected db options: %+v", db)
+	}
+	web := comps[1].Options
+	if !reflect.DeepEqual(web.VarFiles, base.VarFiles) || !reflect.DeepEqual(web.Targets, base.Targets) || web.NoLock || web.Init {
+		t.Fatalf("unexpected web options: %+v", web)
+	}
+
+	if _, err := parseComponents([]map[string]map[string]any{{"a": {"lock_timeout": "soon"}}}, base); err == nil {
+		t.Fatal("expected an error for an invalid lock_timeout")
+	}
+}
+
+func TestApplyPlanOptions(t *testing.T) {
+	no := false
+	cfg := PlanConfig{
+		VarFiles:    []string{"prod.tfvars"},
+		Vars:        map[string]string{"region": "eu-west-1"},
+		Lock:        &no,
+		LockTimeout: time.Minute,
+		Parallelism: 8,
+	}
+
+	cmd := &cobra.Command{}
+	addPlanFlags(cmd)
+	var o drift.Options
+	if err := applyPlanOptions(cmd, &o, cfg); err != nil {
+		t.Fatalf("applyPlanOptions error: %v", err)
+	}
+	if !reflect.DeepEqual(o.VarFiles, cfg.VarFiles) || !reflect.DeepEqual(o.Vars, []string{"region=eu-west-1"}) ||
+		!o.NoLock || o.LockTimeout != time.Minute || o.Parallelism != 8 {
+		t.Fatalf("config not applied: %+v", o)
+	}
+
+	// Flags override the config file.
+	if err := cmd.ParseFlags([]string{"--var-file", "dev.tfvars", "--var", "region=us-east-1", "--lock=true", "--tf-parallelism", "2",
+		"--backend-config", "prod.tfbackend"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyPlanOptions(cmd, &o, cfg); err != nil {
+		t.Fatalf("applyPlanOptions error: %v", err)
+	}
+	if !reflect.DeepEqual(o.VarFiles, []string{"dev.tfvars"}) || !reflect.DeepEqual(o.Vars, []string{"region=us-east-1"}) ||
+		o.NoLock || o.Parallelism != 2 || !o.Init || !reflect.DeepEqual(o.BackendConfig, []string{"prod.tfbackend"}) {
+		t.Fatalf("flags did not override config: %+v", o)
+	}
+
+	cmd = &cobra.Command{}
+	addPlanFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--var", "novalue"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyPlanOptions(cmd, &o, PlanConfig{}); err == nil {
+		t.Fatal("expected an error for --var without '='")
+	}
+}
@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/ha36d/drift-checker/internal/plan"
+	"github.com/ha36d/drift-checker/internal/policy"
+	"github.com/ha36d/drift-checker/internal/report"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -16,7 +23,17 @@ var (
 	gateStrict      bool
 	gateMaxDeletes  int
 	gateMaxReplaces int
+	gateMaxDBC      int
 	gateList        bool
+	gateShowSecrets bool
+	gatePolicyPath  string
+	gatePath        string
+	gateRunner      string
+	gateTimeout     time.Duration
+	gateAllowReason []string
+
+	// Test hook (overridable in tests)
+	gateShowPlanJSON = plan.ShowPlanJSON
 )
 
 // gateCmd enforces destructive-change policy (delete/replace) on a normal plan JSON.
@@ -24,44 +41,126 @@ var gateCmd = &cobra.Command{
 	Use:   "gate",
 	Short: "Enforce destructive-change policy against a plan JSON (not refresh-only)",
 	Long: `Reads a standard Terraform/OpenTofu plan JSON (from 'show -json') and enforces a
-destructive-change policy. Destructive actions are deletes and replaces.
+destructive-change policy. Destructive actions are deletes and replaces. Moved,
+imported and forgotten (removed from state, not destroyed) resources are reported as
+their own categories and are never destructive on their own.
+
+--input may also be a binary plan file (saved with 'plan -out'); it is converted with
+'<runner> show -json' in --path, which must be the plan's initialized root module.
+Use --input - to read plan JSON from stdin.
+
+With --policy, every resource change is instead evaluated against an ordered list of
+rules (matching type, address, module, provider and action), each resolving to
+deny, warn or allow. Per-resource verdicts are included in the output and only a
+deny fails the gate; --max-deletes/--max-replaces cannot be combined with --policy.
+
+Replacements carry the plan's action_reason. --allow-replace-reason (tainted, requested
+or triggered) reports replaces with that reason without counting them as destructive,
+so re-creating a tainted or explicitly -replace'd resource does not trip --strict or
+--max-replaces. Forced replacements (replace_because_cannot_update) always count; to
+allow some of them, use a --policy rule with a reasons selector.
+
+Replaces are split by ordering: create-before-destroy (["create","delete"], from
+lifecycle.create_before_destroy) keeps the old object until its successor exists, while
+delete-before-create (["delete","create"]) leaves a gap. --max-delete-before-create
+limits the latter only. Deposed objects (left behind by a create-before-destroy replace
+whose apply failed) are reported separately and, when deleted, count as deletes.
 
 Exit codes:
   0 = safe
-  2 = destructive changes present or thresholds exceeded (when --strict)
+  2 = destructive changes present or thresholds exceeded (when --strict), or a policy rule denied a change
   1 = error`,
 	Example: `  drift-checker gate --input plan.json --strict
   drift-checker gate --input plan.json --format json --max-deletes 0 --max-replaces 0 --strict
-  drift-checker gate --input plan.json --format text --list`,
+  drift-checker gate --input plan.json --format text --list
+  drift-checker gate --input plan.json --max-delete-before-create 0 --strict
+  drift-checker gate --input plan.json --policy gate-policy.yaml
+  drift-checker gate --input plan.json --format sarif --path infra > gate.sarif
+  drift-checker gate --input infra/plan.tfplan --path infra --strict
+  tofu show -json plan.tfplan | drift-checker gate --input - --strict`,
 	RunE: runGate,
 }
 
 func init() {
 	rootCmd.AddCommand(gateCmd)
 
-	gateCmd.Flags().StringVar(&gateInputPath, "input", "", "path to a normal plan JSON file (from 'show -json') [required]")
+	gateCmd.Flags().StringVar(&gateInputPath, "input", "", "plan JSON (from 'show -json') or binary plan file, or - for JSON on stdin [required]")
 	gateCmd.MarkFlagRequired("input")
 
-	gateCmd.Flags().StringVar(&gateFormat, "format", "md", "output format: md|json|text")
+	gateCmd.Flags().StringVar(&gateFormat, "format", "md", "output format: md|json|text|sarif|junit")
+	gateCmd.Flags().StringVar(&gatePath, "path", ".", "working directory containing the Terraform/OpenTofu configuration (used to resolve source locations)")
 	gateCmd.Flags().BoolVar(&gateStrict, "strict", false, "exit with code 2 if destructive changes are present or thresholds exceeded")
 	gateCmd.Flags().IntVar(&gateMaxDeletes, "max-deletes", -1, "maximum allowed deletes before failing (negative means unlimited)")
 	gateCmd.Flags().IntVar(&gateMaxReplaces, "max-replaces", -1, "maximum allowed replaces before failing (negative means unlimited)")
-	gateCmd.Flags().BoolVar(&gateList, "list", false, "include list of destructive resource addresses in the output")
+	gateCmd.Flags().IntVar(&gateMaxDBC, "max-delete-before-create", -1, "maximum allowed delete-before-create replaces before failing (negative means unlimited)")
+	gateCmd.Flags().BoolVar(&gateList, "list", false, "include list of destructive resource addresses (with attribute diffs) in the output")
+	gateCmd.Flags().StringVar(&gatePolicyPath, "policy", "", "YAML policy file with ordered deny/warn/allow rules (replaces --max-deletes/--max-replaces)")
+	gateCmd.Flags().StringVar(&gateRunner, "runner", "auto", "runner used to convert binary plans: tofu|terraform|terragrunt|auto")
+	gateCmd.Flags().DurationVar(&gateTimeout, "timeout", 10*time.Minute, "timeout for converting a binary plan")
+	gateCmd.Flags().StringSliceVar(&gateAllowReason, "allow-replace-reason", nil, "do not count tainted, requested (-replace) or triggered (replace_triggered_by) replaces as destructive: tainted|requested|triggered (repeatable)")
+	gateCmd.Flags().BoolVar(&gateShowSecrets, "show-sensitive", false, "print sensitive attribute values (only allowed when stdout is a terminal)")
 }
 
 type gatePayload struct {
-	Updates           int      `json:"updates"`
-	Replaces          int      `json:"replaces"`
-	Deletes           int      `json:"deletes"`
-	DestructiveTotal  int      `json:"destructive_total"`
-	Destructive       []string `json:"destructive,omitempty"`
-	TotalResourceRefs int      `json:"total"` // equivalent to len(stats.DriftedResources)
+	FormatVersion    string   `json:"format_version,omitempty"`
+	TerraformVersion string   `json:"terraform_version,omitempty"`
+	Updates          int      `json:"updates"`
+	Replaces         int      `json:"replaces"`
+	Deletes          int      `json:"deletes"`
+	DestructiveTotal int      `json:"destructive_total"`
+	Destructive      []string `json:"destructive,omitempty"`
+	AllowedReplaces  int      `json:"allowed_replaces,omitempty"` // replaces excluded by --allow-replace-reason
+	AllowedReplaced  []string `json:"allowed_replaced,omitempty"`
+	// Replaces split by ordering: ["create","delete"] and ["delete","create"].
+	CreateBeforeDestroy int      `json:"create_before_destroy"`
+	DeleteBeforeCreate  int      `json:"delete_before_create"`
+	Deposed             int      `json:"deposed"`
+	DeposedObjects      []string `json:"deposed_objects,omitempty"` // with --list
+	TotalResourceRefs   int      `json:"total"`                     // equivalent to len(stats.DriftedResources)
+	ExternalDrift       int      `json:"external_drift"`
+	ExternalDrifted     []string `json:"external_drifted,omitempty"`
+	Moves               int      `json:"moves"`
+	Imports             int      `json:"imports"`
+	Forgets             int      `json:"forgets"`
+
+	// Moved, imported and forgotten resources (with --list).
+	Refactors []report.JSONRefactor `json:"refactors,omitempty"`
+	refactors []plan.Change
+
+	// Attribute diffs of the destructive resources (with --list); sensitive values are masked.
+	DestructiveChanges []report.JSONChange `json:"destructive_changes,omitempty"`
+	changes            []plan.Change
+
+	// Per-resource policy verdicts (with --policy).
+	Verdicts []policy.Verdict `json:"verdicts,omitempty"`
+	Denied   int              `json:"denied,omitempty"`
+	Warned   int              `json:"warned,omitempty"`
 }
 
 func runGate(cmd *cobra.Command, args []string) error {
-	b, err := os.ReadFile(gateInputPath)
+	if err := checkShowSensitive(gateShowSecrets); err != nil {
+		return err
+	}
+
+	var pol *policy.File
+	if gatePolicyPath != "" {
+		if gateMaxDeletes >= 0 || gateMaxReplaces >= 0 || gateMaxDBC >= 0 || len(gateAllowReason) > 0 {
+			return fmt.Errorf("--policy cannot be combined with --max-deletes/--max-replaces/--max-delete-before-create/--allow-replace-reason")
+		}
+		var err error
+		if pol, err = policy.Load(gatePolicyPath); err != nil {
+			return fmt.Errorf("load --policy: %w", err)
+		}
+	}
+
+	allowedReasons, err := parseReplaceReasons(gateAllowReason)
 	if err != nil {
-		return fmt.Errorf("read --input: %w", err)
+		return err
+	}
+
+	b, err := readGateInput()
+	if err != nil {
+		return err
 	}
 
 	// Reuse the plan parser for counts and total drifted list.
@@ -69,23 +168,48 @@ func runGate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid plan JSON: %w", err)
 	}
-
-	// Extract *destructive-only* addresses (delete/replace) for listing/JSON.
-	destructiveAddrs, err := extractDestructiveAddresses(b)
-	if err != nil {
-		return fmt.Errorf("failed to extract destructive addresses: %w", err)
+	stats = applySensitivity(stats, gateShowSecrets)
+	for _, w := range stats.Warnings {
+		log.Warn(w)
 	}
 
+	// *Destructive-only* addresses (delete/replace) for listing/JSON.
+	destructiveAddrs, allowedAddrs := destructiveAddresses(stats.Resources, allowedReasons)
+	replaces := stats.Replaces - len(allowedAddrs)
+	deleteFirst := deleteBeforeCreateCount(stats.Resources, destructiveAddrs)
+
 	payload := gatePayload{
-		Updates:           stats.Updates,
-		Replaces:          stats.Replaces,
-		Deletes:           stats.Deletes,
-		DestructiveTotal:  stats.Replaces + stats.Deletes,
-		Destructive:       nil,
-		TotalResourceRefs: len(stats.DriftedResources),
+		FormatVersion:       stats.FormatVersion,
+		TerraformVersion:    stats.TerraformVersion,
+		Updates:             stats.Updates,
+		Replaces:            stats.Replaces,
+		Deletes:             stats.Deletes,
+		DestructiveTotal:    replaces + stats.Deletes,
+		Destructive:         nil,
+		AllowedReplaces:     len(allowedAddrs),
+		CreateBeforeDestroy: stats.CreateBeforeDestroy,
+		DeleteBeforeCreate:  stats.DeleteBeforeCreate,
+		Deposed:             len(stats.Deposed),
+		TotalResourceRefs:   len(stats.DriftedResources),
+		ExternalDrift:       stats.External.Count(),
+		Moves:               stats.Moves,
+		Imports:             stats.Imports,
+		Forgets:             stats.Forgets,
 	}
 	if gateList {
 		payload.Destructive = destructiveAddrs
+		payload.AllowedReplaced = allowedAddrs
+		payload.DeposedObjects = report.ObjectAddresses(stats.Deposed)
+		payload.ExternalDrifted = stats.External.Resources
+		payload.refactors = report.Refactors(stats.Resources)
+		payload.Refactors = report.JSONRefactors(payload.refactors)
+		payload.changes = destructiveChanges(stats.Changes, append(slices.Clone(destructiveAddrs), allowedAddrs...))
+		payload.DestructiveChanges = report.JSONChanges(payload.changes)
+	}
+	if pol != nil {
+		payload.Verdicts = pol.Evaluate(stats.Resources)
+		payload.Denied = policy.Count(payload.Verdicts, policy.OutcomeDeny)
+		payload.Warned = policy.Count(payload.Verdicts, policy.OutcomeWarn)
 	}
 
 	// Render per requested format (stdout only). All logs go to stderr.
@@ -100,15 +224,36 @@ func runGate(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to render json: %w", err)
 		}
 		fmt.Println(string(js))
+	case "sarif":
+		sarif, err := report.BuildSARIF(gateSARIFResults(stats, destructiveAddrs, payload.Verdicts), report.VersionProperties(stats, plan.RunnerVersion{}))
+		if err != nil {
+			return fmt.Errorf("failed to render sarif: %w", err)
+		}
+		fmt.Println(sarif)
+	case "junit":
+		xml, err := report.BuildJUnit([]report.JUnitSuite{gateJUnitSuite(stats, destructiveAddrs, payload.Verdicts)})
+		if err != nil {
+			return fmt.Errorf("failed to render junit: %w", err)
+		}
+		fmt.Println(xml)
 	default:
-		return fmt.Errorf("unsupported format %q (use md|text|json)", gateFormat)
+		return fmt.Errorf("unsupported format %q (use md|text|json|sarif|junit)", gateFormat)
+	}
+
+	// Policy gating: only deny verdicts fail, regardless of --strict.
+	if pol != nil {
+		if payload.Denied > 0 {
+			os.Exit(2)
+		}
+		return nil
 	}
 
 	// Strict policy gating: destructive present OR thresholds exceeded
 	thresholdHit := (gateMaxDeletes >= 0 && stats.Deletes > gateMaxDeletes) ||
-		(gateMaxReplaces >= 0 && stats.Replaces > gateMaxReplaces)
+		(gateMaxReplaces >= 0 && replaces > gateMaxReplaces) ||
+		(gateMaxDBC >= 0 && deleteFirst > gateMaxDBC)
 
-	destructivePresent := (stats.Deletes > 0 || stats.Replaces > 0)
+	destructivePresent := (stats.Deletes > 0 || replaces > 0)
 
 	if gateStrict && (destructivePresent || thresholdHit) {
 		// Use os.Exit to meet the precise exit-code contract.
@@ -117,13 +262,18 @@ func runGate(cmd *cobra.Command, args []string) error {
 
 	// Log some context for troubleshooting (stderr).
 	log.WithFields(log.Fields{
-		"updates":           stats.Updates,
-		"replaces":          stats.Replaces,
-		"deletes":           stats.Deletes,
-		"destructive_total": payload.DestructiveTotal,
-		"max_deletes":       gateMaxDeletes,
-		"max_replaces":      gateMaxReplaces,
-		"strict":            gateStrict,
+		"updates":                  stats.Updates,
+		"replaces":                 stats.Replaces,
+		"allowed_replaces":         payload.AllowedReplaces,
+		"delete_before_create":     deleteFirst,
+		"deposed":                  payload.Deposed,
+		"deletes":                  stats.Deletes,
+		"destructive_total":        payload.DestructiveTotal,
+		"external_drift":           payload.ExternalDrift,
+		"max_deletes":              gateMaxDeletes,
+		"max_replaces":             gateMaxReplaces,
+		"max_delete_before_create": gateMaxDBC,
+		"strict":                   gateStrict,
 	}).Debug("gate evaluation")
 
 	return nil
@@ -132,73 +282,324 @@ func runGate(cmd *cobra.Command, args []string) error {
 func renderGateMarkdown(p gatePayload) string {
 	var s string
 	s += "## Destructive Change Gate\n\n"
+	if v := planVersionText(p); v != "" {
+		s += fmt.Sprintf("- **Plan format**: %s\n", v)
+	}
 	s += fmt.Sprintf("- **Updates**: %d\n", p.Updates)
 	s += fmt.Sprintf("- **Replaces**: %d\n", p.Replaces)
+	if p.Replaces > 0 {
+		s += fmt.Sprintf("  - **Create before destroy**: %d\n", p.CreateBeforeDestroy)
+		s += fmt.Sprintf("  - **Delete before create**: %d\n", p.DeleteBeforeCreate)
+	}
 	s += fmt.Sprintf("- **Deletes**: %d\n", p.Deletes)
+	if p.Deposed > 0 {
+		s += fmt.Sprintf("- **Deposed objects**: %d\n", p.Deposed)
+	}
+	if p.AllowedReplaces > 0 {
+		s += fmt.Sprintf("- **Allowed replaces (by reason)**: %d\n", p.AllowedReplaces)
+	}
 	s += fmt.Sprintf("- **Destructive total (delete+replace)**: %d\n", p.DestructiveTotal)
 	s += fmt.Sprintf("- **Total changed resources in plan**: %d\n", p.TotalResourceRefs)
+	s += fmt.Sprintf("- **External drift (changed outside IaC)**: %d\n", p.ExternalDrift)
+	if p.Moves+p.Imports+p.Forgets > 0 {
+		s += report.MarkdownRefactorCounts(p.Moves, p.Imports, p.Forgets)
+	}
 	if len(p.Destructive) > 0 {
 		s += "\n### Destructive Resources\n\n"
-		for _, a := range p.Destructive {
+		s += report.MarkdownChanges(p.Destructive, p.changes)
+	}
+	if len(p.AllowedReplaced) > 0 {
+		s += "\n### Allowed Replacements\n\n"
+		s += report.MarkdownChanges(p.AllowedReplaced, p.changes)
+	}
+	if len(p.DeposedObjects) > 0 {
+		s += "\n### Deposed Objects\n\n"
+		for _, a := range p.DeposedObjects {
+			s += fmt.Sprintf("- `%s`\n", a)
+		}
+	}
+	if len(p.refactors) > 0 {
+		s += "\n### Moved, Imported and Forgotten Resources\n\n"
+		s += report.MarkdownRefactors(p.refactors)
+	}
+	if len(p.ExternalDrifted) > 0 {
+		s += "\n### External Drift\n\n"
+		for _, a := range p.ExternalDrifted {
 			s += fmt.Sprintf("- `%s`\n", a)
 		}
 	}
+	if len(p.Verdicts) > 0 {
+		s += fmt.Sprintf("\n### Policy Verdicts (%d denied, %d warned)\n\n", p.Denied, p.Warned)
+		for _, v := range p.Verdicts {
+			s += fmt.Sprintf("- **%s** `%s` (%s)", v.Outcome.Label(), v.Address, verdictAction(v))
+			if v.Message != "" {
+				s += ": " + v.Message
+			}
+			s += "\n"
+		}
+	}
 	return s
 }
 
 func renderGateText(p gatePayload) string {
 	var s string
 	s += "Destructive Change Gate\n"
+	if v := planVersionText(p); v != "" {
+		s += fmt.Sprintf("Plan format: %s\n", v)
+	}
 	s += fmt.Sprintf("Updates: %d\n", p.Updates)
 	s += fmt.Sprintf("Replaces: %d\n", p.Replaces)
+	if p.Replaces > 0 {
+		s += fmt.Sprintf("  Create before destroy: %d\n", p.CreateBeforeDestroy)
+		s += fmt.Sprintf("  Delete before create: %d\n", p.DeleteBeforeCreate)
+	}
 	s += fmt.Sprintf("Deletes: %d\n", p.Deletes)
+	if p.Deposed > 0 {
+		s += fmt.Sprintf("Deposed objects: %d\n", p.Deposed)
+	}
+	if p.AllowedReplaces > 0 {
+		s += fmt.Sprintf("Allowed replaces (by reason): %d\n", p.AllowedReplaces)
+	}
 	s += fmt.Sprintf("Destructive total (delete+replace): %d\n", p.DestructiveTotal)
 	s += fmt.Sprintf("Total changed resources in plan: %d\n", p.TotalResourceRefs)
+	s += fmt.Sprintf("External drift (changed outside IaC): %d\n", p.ExternalDrift)
+	if p.Moves+p.Imports+p.Forgets > 0 {
+		s += report.TextRefactorCounts(p.Moves, p.Imports, p.Forgets)
+	}
 	if len(p.Destructive) > 0 {
 		s += "\nDestructive Resources:\n"
-		for _, a := range p.Destructive {
+		s += report.TextChanges(p.Destructive, p.changes)
+	}
+	if len(p.AllowedReplaced) > 0 {
+		s += "\nAllowed Replacements:\n"
+		s += report.TextChanges(p.AllowedReplaced, p.changes)
+	}
+	if len(p.DeposedObjects) > 0 {
+		s += "\nDeposed Objects:\n"
+		for _, a := range p.DeposedObjects {
+			s += fmt.Sprintf("- %s\n", a)
+		}
+	}
+	if len(p.refactors) > 0 {
+		s += "\nMoved, Imported and Forgotten Resources:\n"
+		s += report.TextRefactors(p.refactors)
+	}
+	if len(p.ExternalDrifted) > 0 {
+		s += "\nExternal Drift:\n"
+		for _, a := range p.ExternalDrifted {
 			s += fmt.Sprintf("- %s\n", a)
 		}
 	}
+	if len(p.Verdicts) > 0 {
+		s += fmt.Sprintf("\nPolicy Verdicts (%d denied, %d warned):\n", p.Denied, p.Warned)
+		for _, v := range p.Verdicts {
+			s += fmt.Sprintf("- %s %s (%s)", v.Outcome.Label(), v.Address, verdictAction(v))
+			if v.Message != "" {
+				s += ": " + v.Message
+			}
+			s += "\n"
+		}
+	}
 	return s
 }
 
-// Local minimal structs to extract *destructive* addresses without changing internal/plan API.
-type gatePlan struct {
-	ResourceChanges []gateRC `json:"resource_changes"`
+// readGateInput returns the plan JSON named by --input, converting binary plan
+// files with the runner's show -json.
+func readGateInput() ([]byte, error) {
+	if gateInputPath == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read --input from stdin: %w", err)
+		}
+		if plan.IsBinaryPlan(b) {
+			return nil, errors.New("binary plans cannot be read from stdin; pass the plan file path to --input")
+		}
+		return b, nil
+	}
+
+	b, err := os.ReadFile(gateInputPath)
+	if err != nil {
+		return nil, fmt.Errorf("read --input: %w", err)
+	}
+	if !plan.IsBinaryPlan(b) {
+		return b, nil
+	}
+
+	runner, err := plan.ParseRunner(gateRunner)
+	if err != nil {
+		return nil, err
+	}
+	if runner == "" {
+		if runner, err = plan.SelectRunnerFor(gatePath, plan.SelectRunner); err != nil {
+			return nil, err
+		}
+	}
+	log.WithFields(log.Fields{"input": gateInputPath, "path": gatePath, "runner": runner}).Info("Converting binary plan to JSON")
+
+	ctx, cancel := context.WithTimeout(context.Background(), gateTimeout)
+	defer cancel()
+	b, err = gateShowPlanJSON(ctx, runner, gatePath, gateInputPath, plan.PlanOptions{})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("converting binary plan timed out after %v", gateTimeout)
+		}
+		return nil, fmt.Errorf("convert binary plan: %w", err)
+	}
+	return b, nil
 }
-type gateRC struct {
-	Address string    `json:"address"`
-	Change  gateDelta `json:"change"`
+
+func gateSuiteName() string {
+	if gateInputPath == "-" {
+		return "stdin"
+	}
+	return gateInputPath
 }
-type gateDelta struct {
-	Actions []string `json:"actions"`
+
+func planVersionText(p gatePayload) string {
+	return report.PlanVersion(plan.Stats{FormatVersion: p.FormatVersion, TerraformVersion: p.TerraformVersion})
 }
 
-func extractDestructiveAddresses(planJSON []byte) ([]string, error) {
-	var p gatePlan
-	if err := json.Unmarshal(planJSON, &p); err != nil {
-		return nil, err
+// gateSARIFResults reports destructive resources (or, with --policy, denied and
+// warned verdicts) as SARIF results with rule ids such as gate/delete.
+func gateSARIFResults(stats plan.Stats, destructive []string, verdicts []policy.Verdict) []report.SARIFResult {
+	var out []report.SARIFResult
+	if verdicts != nil {
+		for _, v := range verdicts {
+			level := report.LevelError
+			switch v.Outcome {
+			case policy.OutcomeAllow:
+				continue
+			case policy.OutcomeWarn:
+				level = report.LevelWarning
+			}
+			c, _ := plan.FindChange(stats.Resources, v.Address)
+			msg := fmt.Sprintf("%s (%s) %s by policy rule #%d", v.Address, v.Action, v.Outcome.Past(), v.Rule)
+			if v.Message != "" {
+				msg += ": " + v.Message
+			}
+			out = append(out, report.NewSARIFResult("gate/"+string(v.Action), level, msg, c, stats, gatePath))
+		}
+		return out
+	}
+	for _, addr := range destructive {
+		c, _ := plan.FindChange(stats.Resources, addr)
+		msg := fmt.Sprintf("%s is a destructive change (%s)", addr, c.Action())
+		if r := report.ReasonText(c); r != "" {
+			msg += ": " + r
+		}
+		out = append(out, report.NewSARIFResult("gate/"+string(c.Action()), report.LevelError, msg, c, stats, gatePath))
+	}
+	return out
+}
+
+// gateJUnitSuite turns every resource in the plan into a test case that fails
+// when it is destructive (or, with --policy, when a rule denies it).
+func gateJUnitSuite(stats plan.Stats, destructive []string, verdicts []policy.Verdict) report.JUnitSuite {
+	suite := report.JUnitSuite{Name: gateSuiteName(), Properties: report.VersionProperties(stats, plan.RunnerVersion{})}
+	byAddr := map[string]policy.Verdict{}
+	for _, v := range verdicts {
+		byAddr[v.Address] = v
+	}
+	for _, r := range stats.Resources {
+		tc := report.JUnitCase{Name: r.ObjectAddress(), ClassName: "gate"}
+		if verdicts != nil {
+			if v, ok := byAddr[r.ObjectAddress()]; ok && v.Outcome == policy.OutcomeDeny {
+				tc.Failure = fmt.Sprintf("%s denied by policy rule #%d", v.Action, v.Rule)
+				if v.Message != "" {
+					tc.Failure += ": " + v.Message
+				}
+			}
+		} else if slices.Contains(destructive, r.ObjectAddress()) {
+			tc.Failure = fmt.Sprintf("destructive change (%s)", r.Action())
+			if reason := report.ReasonText(r); reason != "" {
+				tc.Failure += ": " + reason
+			}
+		}
+		if tc.Failure != "" {
+			tc.Body = report.ChangeDetails(r, stats.Resources)
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	return suite
+}
+
+// destructiveAddresses returns the addresses of deletes and replaces, in plan
+// order, and separately the replaces whose action_reason is allowed. Forgotten
+// resources are removed from state only and are not included, and data
+// sources are skipped like in the plan.Stats counts.
+func destructiveAddresses(changes []plan.Change, allowedReasons []string) (destructive, allowed []string) {
+	destructive = make([]string, 0, len(changes))
+	for _, c := range changes {
+		if c.IsData() {
+			continue
+		}
+		switch c.Action() {
+		case plan.ActionReplace:
+			if slices.Contains(allowedReasons, c.ActionReason) {
+				allowed = append(allowed, c.ObjectAddress())
+				continue
+			}
+			fallthrough
+		case plan.ActionDelete:
+			destructive = append(destructive, c.ObjectAddress())
+		}
 	}
-	out := make([]string, 0, len(p.ResourceChanges))
-	for _, rc := range p.ResourceChanges {
-		acts := rc.Change.Actions
-		if isDeleteOnly(acts) || isReplace(acts) {
-			out = append(out, rc.Address)
+	return destructive, allowed
+}
+
+// deleteBeforeCreateCount returns how many of the destructive addresses are
+// delete-before-create replaces.
+func deleteBeforeCreateCount(changes []plan.Change, destructive []string) int {
+	n := 0
+	for _, c := range changes {
+		if c.Action() == plan.ActionReplace && !c.CreateBeforeDestroy() && slices.Contains(destructive, c.ObjectAddress()) {
+			n++
+		}
+	}
+	return n
+}
+
+// replaceReasonAliases are the short names accepted by --allow-replace-reason.
+var replaceReasonAliases = map[string]string{
+	"tainted":   plan.ReasonReplaceTainted,
+	"requested": plan.ReasonReplaceByRequest,
+	"triggered": plan.ReasonReplaceByTriggers,
+}
+
+// parseReplaceReasons resolves --allow-replace-reason values to action_reason
+// values. Only the aliases (or their full action_reason) are accepted: forced
+// replacements must not be waved through by the same flag.
+func parseReplaceReasons(names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if r, ok := replaceReasonAliases[n]; ok {
+			n = r
+		}
+		switch n {
+		case plan.ReasonReplaceTainted, plan.ReasonReplaceByRequest, plan.ReasonReplaceByTriggers:
+			out = append(out, n)
+		default:
+			return nil, fmt.Errorf("invalid --allow-replace-reason %q (use tainted|requested|triggered; forced replacements can only be allowed by a --policy rule)", n)
 		}
 	}
 	return out, nil
 }
 
-func isDeleteOnly(acts []string) bool {
-	return len(acts) == 1 && acts[0] == "delete"
+// verdictAction describes the action of a verdict, with its action_reason when known.
+func verdictAction(v policy.Verdict) string {
+	if v.Reason == "" {
+		return string(v.Action)
+	}
+	return fmt.Sprintf("%s, %s", v.Action, v.Reason)
 }
 
-func isReplace(acts []string) bool {
-	if len(acts) != 2 {
-		return false
+// destructiveChanges returns the parsed changes for the given destructive addresses.
+func destructiveChanges(changes []plan.Change, addrs []string) []plan.Change {
+	out := make([]plan.Change, 0, len(addrs))
+	for _, a := range addrs {
+		if c, ok := plan.FindChange(changes, a); ok {
+			out = append(out, c)
+		}
 	}
-	// any order of ["create","delete"] is a replace
-	first, second := acts[0], acts[1]
-	return (first == "create" && second == "delete") || (first == "delete" && second == "create")
-}
\ No newline at end of file
+	return out
+}
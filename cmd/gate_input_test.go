@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// runGateJSON runs the gate with JSON output on input and returns the payload.
+func runGateJSON(t *testing.T, input string) map[string]any {
+	t.Helper()
+	gateInputPath = input
+	gateFormat = "json"
+	gateStrict = false
+	gateMaxDeletes = -1
+	gateMaxReplaces = -1
+	gateMaxDBC = -1
+
+	r, w, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = w
+	err := runGate(nil, nil)
+	w.Close()
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatalf("runGate error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("io.Copy: %v", err)
+	}
+	r.Close()
+	var payload map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, buf.String())
+	}
+	return payload
+}
+
+func TestGate_BinaryPlanInput(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("..", "internal", "plan", "testdata", "plan_drift.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "plan.tfplan")
+	if err := os.WriteFile(binary, []byte("PK\x03\x04not really a zip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotRunner plan.RunnerKind
+	var gotPath, gotFile string
+	orig := gateShowPlanJSON
+	gateShowPlanJSON = func(_ context.Context, runner plan.RunnerKind, path, planFile string, _ plan.PlanOptions) ([]byte, error) {
+		gotRunner, gotPath, gotFile = runner, path, planFile
+		return fixture, nil
+	}
+	gateRunner, gatePath = "terraform", "infra"
+	defer func() { gateShowPlanJSON, gateRunner, gatePath = orig, "auto", "." }()
+
+	payload := runGateJSON(t, binary)
+	if gotRunner != plan.RunnerTerraform || gotPath != "infra" || gotFile != binary {
+		t.Fatalf("show -json called with runner=%q path=%q file=%q", gotRunner, gotPath, gotFile)
+	}
+	if payload["destructive_total"] != float64(2) {
+		t.Fatalf("expected destructive_total 2 from the converted plan, got %v", payload["destructive_total"])
+	}
+}
+
+func TestGate_StdinInput(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "internal", "plan", "testdata", "plan_updates_only.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	payload := runGateJSON(t, "-")
+	if payload["updates"] == float64(0) || payload["destructive_total"] != float64(0) {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+}
+
+func TestGate_RefactorNotDestructive(t *testing.T) {
+	gateList = true
+	t.Cleanup(func() { gateList = false })
+	payload := runGateJSON(t, filepath.Join("..", "internal", "plan", "testdata", "plan_refactor.json"))
+
+	if payload["destructive_total"] != float64(0) || payload["moves"] != float64(2) ||
+		payload["imports"] != float64(1) || payload["forgets"] != float64(1) {
+		t.Fatalf("unexpected counts: %v", payload)
+	}
+	refactors, _ := payload["refactors"].([]any)
+	if len(refactors) != 4 {
+		t.Fatalf("expected 4 refactored resources, got %v", payload["refactors"])
+	}
+}
+
+func TestGate_AllowReplaceReason(t *testing.T) {
+	input := filepath.Join("..", "internal", "plan", "testdata", "plan_replace_reasons.json")
+	gateList = true
+	gateAllowReason = []string{"tainted", "requested"}
+	t.Cleanup(func() { gateList, gateAllowReason = false, nil })
+
+	payload := runGateJSON(t, input)
+	if payload["replaces"] != float64(4) || payload["allowed_replaces"] != float64(2) || payload["destructive_total"] != float64(3) {
+		t.Fatalf("unexpected counts: %v", payload)
+	}
+	allowed, _ := payload["allowed_replaced"].([]any)
+	if len(allowed) != 2 || allowed[0] != "aws_instance.web[0]" || allowed[1] != "aws_instance.worker" {
+		t.Fatalf("unexpected allowed replacements: %v", payload["allowed_replaced"])
+	}
+	for _, c := range payload["destructive_changes"].([]any) {
+		if c.(map[string]any)["address"] == "aws_db_instance.main" && c.(map[string]any)["action_reason"] != "replace_because_cannot_update" {
+			t.Fatalf("action_reason missing: %v", c)
+		}
+	}
+
+	gateInputPath = input
+	for _, reason := range []string{"whatever", plan.ReasonReplaceCannotUpdate} {
+		gateAllowReason = []string{reason}
+		if err := runGate(nil, nil); err == nil || !strings.Contains(err.Error(), "--allow-replace-reason") {
+			t.Fatalf("%s: expected an invalid reason error, got %v", reason, err)
+		}
+	}
+}
+
+func TestGate_ReplaceOrderAndDeposed(t *testing.T) {
+	gateList = true
+	t.Cleanup(func() { gateList = false })
+
+	payload := runGateJSON(t, filepath.Join("..", "internal", "plan", "testdata", "plan_replace_order.json"))
+	if payload["create_before_destroy"] != float64(1) || payload["delete_before_create"] != float64(1) || payload["deposed"] != float64(1) {
+		t.Fatalf("unexpected counts: %v", payload)
+	}
+	if payload["destructive_total"] != float64(3) {
+		t.Fatalf("expected the deposed delete to be destructive: %v", payload)
+	}
+	deposed, _ := payload["deposed_objects"].([]any)
+	if len(deposed) != 1 || deposed[0] != "aws_instance.web (deposed object 1a2b3c4d)" {
+		t.Fatalf("unexpected deposed_objects: %v", payload["deposed_objects"])
+	}
+
+	b, err := os.ReadFile(filepath.Join("..", "internal", "plan", "testdata", "plan_replace_order.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := plan.ParseStats(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	destructive, _ := destructiveAddresses(stats.Resources, nil)
+	if n := deleteBeforeCreateCount(stats.Resources, destructive); n != 1 {
+		t.Fatalf("deleteBeforeCreateCount() = %d, want 1", n)
+	}
+	destructive, _ = destructiveAddresses(stats.Resources, []string{plan.ReasonReplaceCannotUpdate})
+	if n := deleteBeforeCreateCount(stats.Resources, destructive); n != 0 {
+		t.Fatalf("deleteBeforeCreateCount() with allowed reasons = %d, want 0", n)
+	}
+}
+
+func TestDestructiveAddresses_SkipsDataSources(t *testing.T) {
+	changes := []plan.Change{
+		{Address: "aws_instance.web", Mode: plan.ModeManaged, Actions: []string{"delete"}},
+		{Address: "data.aws_ami.ubuntu", Mode: plan.ModeData, Actions: []string{"delete", "create"}},
+	}
+	destructive, _ := destructiveAddresses(changes, nil)
+	if len(destructive) != 1 || destructive[0] != "aws_instance.web" {
+		t.Fatalf("destructiveAddresses() = %v, want only the managed resource", destructive)
+	}
+}
@@ -141,3 +141,73 @@ func TestGate_Thresholds_TripExit2(t *testing.T) {
 	}
 	t.Fatalf("expected non-zero exit due to os.Exit(2)")
 }
+
+func TestGate_Policy_DenyExit2(t *testing.T) {
+	if os.Getenv("GATE_HELPER_POLICY") == "1" {
+		// child process
+		devnull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		defer devnull.Close()
+		os.Stdout = devnull
+		os.Stderr = devnull
+
+		gateInputPath = filepath.Join("..", "internal", "plan", "testdata", "plan_drift.json")
+		gatePolicyPath = filepath.Join("..", "internal", "policy", "testdata", "policy.yaml")
+		gateFormat = "json"
+		gateStrict = false // deny verdicts fail even without --strict
+		gateMaxDeletes = -1
+		gateMaxReplaces = -1
+
+		_ = runGate(nil, nil)
+		os.Exit(1)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestGate_Policy_DenyExit2")
+	cmd.Env = append(os.Environ(), "GATE_HELPER_POLICY=1")
+	err := cmd.Run()
+	if ee, ok := err.(*exec.ExitError); !ok || ee.ExitCode() != 2 {
+		t.Fatalf("expected exit code 2, got %v", err)
+	}
+}
+
+func TestGate_Policy_WarnOnlyExit0(t *testing.T) {
+	gateInputPath = filepath.Join("..", "internal", "plan", "testdata", "plan_updates_only.json")
+	gatePolicyPath = filepath.Join("..", "internal", "policy", "testdata", "policy.yaml")
+	defer func() { gatePolicyPath = "" }()
+	gateFormat = "json"
+	gateStrict = true
+	gateMaxDeletes = -1
+	gateMaxReplaces = -1
+
+	r, w, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = w
+
+	err := runGate(nil, nil)
+
+	w.Close()
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatalf("runGate error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, copyErr := io.Copy(&buf, r); copyErr != nil {
+		t.Fatalf("io.Copy: %v", copyErr)
+	}
+	r.Close()
+
+	var payload struct {
+		Denied   int `json:"denied"`
+		Verdicts []struct {
+			Address string `json:"address"`
+			Outcome string `json:"outcome"`
+		} `json:"verdicts"`
+	}
+	if uerr := json.Unmarshal(buf.Bytes(), &payload); uerr != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", uerr, buf.String())
+	}
+	if payload.Denied != 0 || len(payload.Verdicts) != 1 || payload.Verdicts[0].Outcome != "allow" {
+		t.Fatalf("unexpected verdicts: %+v", payload)
+	}
+}
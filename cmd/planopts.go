@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	drift "github.com/ha36d/drift-checker/internal/drift"
+)
+
+var (
+	varFiles      []string
+	planVars      []string
+	targets       []string
+	lockFlag      bool
+	lockTimeout   time.Duration
+	tfParallelism int
+	initFlag      bool
+	backendConfig []string
+	retries       int
+	retryDelay    time.Duration
+	retryMaxDelay time.Duration
+)
+
+func addPlanFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&varFiles, "var-file", nil, "pass -var-file to the plan (repeatable; relative to --path)")
+	cmd.Flags().StringArrayVar(&planVars, "var", nil, "pass -var 'name=value' to the plan (repeatable)")
+	cmd.Flags().StringArrayVar(&targets, "target", nil, "pass -target to the plan (repeatable)")
+	cmd.Flags().BoolVar(&lockFlag, "lock", true, "hold the state lock during the plan (--lock=false passes -lock=false)")
+	cmd.Flags().DurationVar(&lockTimeout, "lock-timeout", 0, "pass -lock-timeout to the plan (0 = runner default)")
+	cmd.Flags().IntVar(&tfParallelism, "tf-parallelism", 0, "pass -parallelism to the plan (0 = runner default)")
+	cmd.Flags().BoolVar(&initFlag, "init", false, "run 'init -input=false -upgrade=false' before the plan")
+	cmd.Flags().StringArrayVar(&backendConfig, "backend-config", nil, "pass -backend-config (file or key=value) to init (repeatable; implies --init)")
+	cmd.Flags().IntVar(&retries, "retries", 0, "retry a plan that failed on state lock contention, throttling or network errors this many times")
+	cmd.Flags().DurationVar(&retryDelay, "retry-delay", 10*time.Second, "wait before the first retry (doubled for each further retry)")
+	cmd.Flags().DurationVar(&retryMaxDelay, "retry-max-delay", 2*time.Minute, "maximum wait between retries")
+}
+
+// applyPlanOptions fills the runner options of o from the plan flags, falling
+// back to the "plan" section of the config file for flags that were not set.
+func applyPlanOptions(cmd *cobra.Command, o *drift.Options, cfg PlanConfig) error {
+	changed := cmd.Flags().Changed
+
+	o.VarFiles = cfg.VarFiles
+	if changed("var-file") {
+		o.VarFiles = varFiles
+	}
+
+	o.Vars = keyValues(cfg.Vars)
+	if changed("var") {
+		for _, v := range planVars {
+			if !strings.Contains(v, "=") {
+				return fmt.Errorf("invalid --var %q (use name=value)", v)
+			}
+		}
+		o.Vars = planVars
+	}
+
+	o.Targets = cfg.Targets
+	if changed("target") {
+		o.Targets = targets
+	}
+
+	o.NoLock = cfg.Lock != nil && !*cfg.Lock
+	if changed("lock") {
+		o.NoLock = !lockFlag
+	}
+
+	o.LockTimeout = cfg.LockTimeout
+	if changed("lock-timeout") {
+		o.LockTimeout = lockTimeout
+	}
+
+	o.Parallelism = cfg.Parallelism
+	if changed("tf-parallelism") {
+		o.Parallelism = tfParallelism
+	}
+	o.Init = cfg.Init
+	if changed("init") {
+		o.Init = initFlag
+	}
+
+	o.BackendConfig = cfg.BackendConfig
+	if changed("backend-config") {
+		o.BackendConfig = backendConfig
+	}
+	if len(o.BackendConfig) > 0 && !changed("init") {
+		o.Init = true
+	}
+
+	o.Retry.Retries = retries
+	if cfg.Retries != nil && !changed("retries") {
+		o.Retry.Retries = *cfg.Retries
+	}
+	o.Retry.Delay = retryDelay
+	if cfg.RetryDelay > 0 && !changed("retry-delay") {
+		o.Retry.Delay = cfg.RetryDelay
+	}
+	o.Retry.MaxDelay = retryMaxDelay
+	if cfg.RetryMaxDelay > 0 && !changed("retry-max-delay") {
+		o.Retry.MaxDelay = cfg.RetryMaxDelay
+	}
+
+	if o.LockTimeout < 0 || o.Parallelism < 0 {
+		return fmt.Errorf("lock timeout and plan parallelism must not be negative")
+	}
+	if o.Retry.Retries < 0 || o.Retry.Delay < 0 || o.Retry.MaxDelay < 0 {
+		return fmt.Errorf("retries and retry delays must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// progressInterval is how often a running plan logs its progress (0 disables
+// progress logging).
+var progressInterval time.Duration
+
+// slowestResources is how many of the slowest refreshes progress logs list.
+const slowestResources = 3
+
+// progressLogger logs the refresh progress of one scan to stderr.
+type progressLogger struct {
+	fields   log.Fields
+	interval time.Duration
+
+	mu       sync.Mutex
+	progress *plan.Progress
+	stop     chan struct{}
+}
+
+// newProgressLogger returns a PlanOptions.Progress callback that logs the
+// progress of the scan named name every progressInterval while its plan runs,
+// and a summary with the slowest resources once it ended. It returns nil when
+// progress logging is disabled.
+func newProgressLogger(name string) func(plan.ProgressEvent) {
+	if progressInterval <= 0 {
+		return nil
+	}
+	l := &progressLogger{fields: log.Fields{}, interval: progressInterval}
+	if name != "" {
+		l.fields["component"] = name
+	}
+	return l.observe
+}
+
+func (l *progressLogger) observe(e plan.ProgressEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e.Type == plan.ProgressDone {
+		if l.stop == nil {
+			return
+		}
+		close(l.stop)
+		l.stop = nil
+		l.log(l.progress.Snapshot(e.Time, slowestResources), "Refresh finished")
+		return
+	}
+
+	if l.stop == nil {
+		// First message of a plan (or of a retried one): start over.
+		l.progress = plan.NewProgress()
+		l.stop = make(chan struct{})
+		go l.tick(l.progress, l.stop)
+	}
+	l.progress.Observe(e)
+	if e.Type == "refresh_complete" {
+		log.WithFields(l.fields).Debugf("Refreshed %s", e.Address)
+	}
+}
+
+func (l *progressLogger) tick(p *plan.Progress, stop chan struct{}) {
+	t := time.NewTicker(l.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-t.C:
+			l.log(p.Snapshot(now, slowestResources), "Refresh in progress")
+		}
+	}
+}
+
+func (l *progressLogger) log(s plan.ProgressSnapshot, msg string) {
+	fields := log.Fields{
+		"refreshed": s.Refreshed,
+		"in_flight": s.InFlight,
+		"elapsed":   s.Elapsed.Round(time.Second),
+	}
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	if len(s.Slowest) > 0 {
+		fields["slowest"] = slowestText(s.Slowest)
+	}
+	log.WithFields(fields).Info(msg)
+}
+
+// slowestText renders timings as "addr (1m5s), addr (40s, running)".
+func slowestText(timings []plan.ResourceTiming) string {
+	parts := make([]string, 0, len(timings))
+	for _, t := range timings {
+		d := t.Duration.Round(time.Second)
+		if t.InFlight {
+			parts = append(parts, fmt.Sprintf("%s (%s, running)", t.Address, d))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s (%s)", t.Address, d))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestSlowestText(t *testing.T) {
+	got := slowestText([]plan.ResourceTiming{
+		{Address: "aws_db_instance.main", Duration: 65 * time.Second, InFlight: true},
+		{Address: "aws_vpc.main", Duration: 2400 * time.Millisecond},
+	})
+	if want := "aws_db_instance.main (1m5s, running), aws_vpc.main (2s)"; got != want {
+		t.Fatalf("slowestText() = %q, want %q", got, want)
+	}
+}
+
+func TestNewProgressLogger(t *testing.T) {
+	old := progressInterval
+	t.Cleanup(func() { progressInterval = old })
+
+	progressInterval = 0
+	if newProgressLogger("app") != nil {
+		t.Fatal("expected no progress callback with a zero interval")
+	}
+
+	progressInterval = time.Hour
+	fn := newProgressLogger("app")
+	now := time.Now()
+	fn(plan.ProgressEvent{Type: "refresh_start", Address: "aws_vpc.main", Time: now})
+	fn(plan.ProgressEvent{Type: "refresh_complete", Address: "aws_vpc.main", Time: now.Add(time.Second)})
+	fn(plan.ProgressEvent{Type: plan.ProgressDone, Time: now.Add(2 * time.Second)})
+	fn(plan.ProgressEvent{Type: plan.ProgressDone, Time: now.Add(3 * time.Second)})
+}
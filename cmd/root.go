@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/xeipuuv/gojsonschema"
+	"go.yaml.in/yaml/v3"
 )
 
 // Config represents the application configuration
@@ -18,6 +20,28 @@ type Config struct {
 	Account    string                      `yaml:"account"`
 	Region     string                      `yaml:"region"`
 	Components []map[string]map[string]any `yaml:"components"`
+	// Runner is the default runner: tofu, terraform, terragrunt or auto.
+	Runner string `yaml:"runner"`
+	// Plan holds the default runner options of every refresh-only plan.
+	Plan PlanConfig `yaml:"plan"`
+	// SensitiveAttributes lists attribute path glob patterns (e.g. "password",
+	// "*secret*", "tags.Token") whose values are always masked in reports.
+	SensitiveAttributes []string `yaml:"sensitive_attributes" mapstructure:"sensitive_attributes"`
+}
+
+// PlanConfig is the "plan" section of the config file. Scan flags override it.
+type PlanConfig struct {
+	VarFiles      []string          `yaml:"var_files" mapstructure:"var_files"`
+	Vars          map[string]string `yaml:"vars"`
+	Targets       []string          `yaml:"targets"`
+	Lock          *bool             `yaml:"lock"`
+	LockTimeout   time.Duration     `yaml:"lock_timeout" mapstructure:"lock_timeout"`
+	Parallelism   int               `yaml:"parallelism"`
+	Init          bool              `yaml:"init"`
+	BackendConfig []string          `yaml:"backend_config" mapstructure:"backend_config"`
+	Retries       *int              `yaml:"retries"`
+	RetryDelay    time.Duration     `yaml:"retry_delay" mapstructure:"retry_delay"`
+	RetryMaxDelay time.Duration     `yaml:"retry_max_delay" mapstructure:"retry_max_delay"`
 }
 
 var (
@@ -115,6 +139,37 @@ func loadAndValidateConfig() error {
 	if err := viper.Unmarshal(&config); err != nil {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err := decodeCaseSensitive(viper.ConfigFileUsed(), &config); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	return nil
+}
+
+// decodeCaseSensitive re-reads the sections of the config file whose keys are
+// case-sensitive (component names, environment variables and Terraform
+// variables), since viper lowercases every map key. Only YAML (and JSON)
+// config files are re-read.
+func decodeCaseSensitive(path string, cfg *Config) error {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var raw struct {
+		Components []map[string]map[string]any `yaml:"components"`
+		Plan       struct {
+			Vars map[string]string `yaml:"vars"`
+		} `yaml:"plan"`
+	}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	cfg.Components = raw.Components
+	cfg.Plan.Vars = raw.Plan.Vars
 	return nil
 }
 
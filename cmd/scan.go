@@ -2,23 +2,44 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	drift "github.com/ha36d/drift-checker/internal/drift"
+	"github.com/ha36d/drift-checker/internal/plan"
+	"github.com/ha36d/drift-checker/internal/report"
 )
 
 var (
-	timeout     time.Duration
-	forceUpdate bool // kept (unused) to avoid breaking previous flags
-	pathFlag    string
-	formatFlag  string
-	strictFlag  bool
+	timeout       time.Duration
+	forceUpdate   bool // kept (unused) to avoid breaking previous flags
+	pathFlag      string
+	formatFlag    string
+	strictFlag    bool
+	showSecrets   bool
+	ignoreFile    string
+	expiredMode   string
+	components    []string
+	parallelism   int
+	compTimeout   time.Duration
+	failFast      bool
+	keepPlan      string
+	runnerFlag    string
+	workspace     string
+	allWorkspaces bool
+	gracePeriod   time.Duration
+	dataSources   bool
+	outputsFlag   bool
 )
 
 // scanCmd represents the infrastructure scan command
@@ -27,15 +48,78 @@ var scanCmd = &cobra.Command{
 	Short: "Scan for drift in Terraform/OpenTofu state via refresh-only plan",
 	Long: `Runs a refresh-only plan using OpenTofu (preferred) or Terraform, parses the JSON plan,
 and reports counts of updates / deletes / replaces, plus the list of drifted resources.
+Drift detected outside of IaC (the plan's resource_drift section) is reported separately.
+
+The runner is chosen with --runner (or the runner config key). With auto (the default),
+Terragrunt is used for directories containing terragrunt.hcl, otherwise tofu, then terraform.
+
+Known, accepted drift can be suppressed with an exemption file (.driftignore.yaml in
+--path, or --ignore-file). Suppressed drift is listed in its own report section.
+
+When the config file lists components (and --path is not given), every component is
+scanned with its own path, runner, workspace, var files and environment, and a single
+aggregated report with one section per component and an overall verdict is printed.
+Up to --parallelism components are scanned at once; the report keeps the config order.
+By default a failing component does not stop the others (--fail-fast cancels them).
+
+--workspace selects a workspace via TF_WORKSPACE (the directory's selected workspace is
+left untouched); --all-workspaces scans every workspace from 'workspace list' and prints
+one aggregated report with a section per workspace (and per component).
+
+--var-file, --var, --target, --lock, --lock-timeout and --tf-parallelism are passed to
+the plan (defaults come from the plan section of the config file) and recorded in the
+JSON report (variable names only).
+
+--init runs 'init -input=false -upgrade=false' (with any --backend-config files or
+key=value pairs) once per directory before planning. Without it, an uninitialized
+directory is reported as a distinct "not initialized" failure.
+
+The plan runs with -json. While it runs, its refresh progress (resources refreshed,
+elapsed time, slowest resources) is logged to stderr every --progress-interval; when it
+fails, its diagnostics (with file:line) are printed as the md/text/json report on stdout
+before exiting 1.
+
+Runner failures are classified (not-initialized, state-locked, throttled, network, auth,
+config). With --retries N, a plan failing on state lock contention, throttling or a
+network error is retried up to N times with exponential backoff (--retry-delay, doubled
+per retry, at most --retry-max-delay), as long as --timeout leaves time for the wait.
+
+Data sources are counted separately from managed resources and never count as drift.
+Reads deferred to apply (because their configuration or a dependency is not known yet)
+are listed with their reason; --data-sources also lists data sources whose read result
+changed, which often explains drift in the resources that use them.
+
+--include-outputs reports root module outputs whose value would change (an endpoint,
+an IP address), so consumers of those outputs (other stacks, DNS) know they are
+affected. Sensitive outputs are masked. Changed outputs do not count as drift.
+
+The binary plan is written to a private temporary directory and removed afterwards;
+use --keep-plan to persist it (with components, --keep-plan is a directory that
+receives one <component>.tfplan per component).
+
+On timeout, Ctrl-C or SIGTERM the runners are interrupted (SIGINT to their process
+group) and get --grace-period to release state locks and exit before they are killed.
+A second Ctrl-C or SIGTERM kills them right away.
 
 Exit status:
   0 = no drift
-  2 = drift detected (when --strict is set)
-  1 = other error`,
+  2 = drift detected (when --strict is set) or an exemption expired (with --on-expired fail)
+  1 = other error (or any component failed to scan)
+  130/143 = killed by a second Ctrl-C / SIGTERM`,
 	Example: `  drift-checker scan
   drift-checker scan --path . --format md --strict
   drift-checker scan --timeout 30m
-  drift-checker scan --format json`,
+  drift-checker scan --format json
+  drift-checker scan --format sarif > drift.sarif
+  drift-checker scan --format junit > drift-junit.xml
+  drift-checker scan --ignore-file ops/driftignore.yaml --on-expired fail --strict
+  drift-checker scan --config ops/.config.yaml --component network --component dns
+  drift-checker scan --parallelism 8 --component-timeout 20m --fail-fast
+  drift-checker scan --path infra --keep-plan drift.tfplan
+  drift-checker scan --path live/prod/vpc --runner terragrunt
+  drift-checker scan --path infra --all-workspaces --parallelism 4
+  drift-checker scan --path infra --include-outputs --format json
+  drift-checker scan --var-file prod.tfvars --var region=eu-west-1 --target module.db --lock=false`,
 	RunE: runScan,
 }
 
@@ -45,24 +129,105 @@ func init() {
 	scanCmd.Flags().DurationVar(&timeout, "timeout", 2*time.Hour, "timeout for the scan operation")
 	scanCmd.Flags().BoolVar(&forceUpdate, "force", false, "deprecated: no-op (kept for compatibility)")
 	scanCmd.Flags().StringVar(&pathFlag, "path", ".", "working directory containing the Terraform/OpenTofu configuration")
-	scanCmd.Flags().StringVar(&formatFlag, "format", "md", "output format: md|text|json")
+	scanCmd.Flags().StringVar(&formatFlag, "format", "md", "output format: md|text|json|sarif|junit")
 	scanCmd.Flags().BoolVar(&strictFlag, "strict", false, "exit with code 2 if drift is detected")
+	scanCmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "drift exemption file (default: .driftignore.yaml in --path, if present)")
+	scanCmd.Flags().StringVar(&expiredMode, "on-expired", "warn", "what to do when an exemption has expired: warn|fail (fail exits with code 2)")
+	scanCmd.Flags().BoolVar(&showSecrets, "show-sensitive", false, "print sensitive attribute values (only allowed when stdout is a terminal)")
+	scanCmd.Flags().StringSliceVar(&components, "component", nil, "only scan the named config components (repeatable)")
+	scanCmd.Flags().IntVar(&parallelism, "parallelism", 1, "number of components scanned concurrently")
+	scanCmd.Flags().DurationVar(&compTimeout, "component-timeout", 0, "timeout for each component scan (0 = bounded by --timeout only)")
+	scanCmd.Flags().BoolVar(&failFast, "fail-fast", false, "cancel the remaining component scans after the first failure")
+	addPlanFlags(scanCmd)
+	scanCmd.Flags().StringVar(&runnerFlag, "runner", "auto", "runner: tofu|terraform|terragrunt|auto (overrides the runner config key)")
+	scanCmd.Flags().StringVar(&workspace, "workspace", "", "scan this workspace (selected via TF_WORKSPACE; overrides component workspaces)")
+	scanCmd.Flags().BoolVar(&allWorkspaces, "all-workspaces", false, "scan every workspace listed by 'workspace list' and aggregate the results")
+	scanCmd.Flags().DurationVar(&gracePeriod, "grace-period", 30*time.Second, "on timeout or interrupt, give the runner this long to release locks and exit before killing it")
+	scanCmd.Flags().DurationVar(&progressInterval, "progress-interval", 30*time.Second, "log the refresh progress to stderr this often while the plan runs (0 disables)")
+	scanCmd.Flags().BoolVar(&dataSources, "data-sources", false, "also report data sources whose read result changed")
+	scanCmd.Flags().BoolVar(&outputsFlag, "include-outputs", false, "also report root module outputs whose value would change")
+	scanCmd.Flags().StringVar(&keepPlan, "keep-plan", "", "persist the binary refresh-only plan at this path (a directory when scanning components)")
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
+	if err := checkShowSensitive(showSecrets); err != nil {
+		return err
+	}
+	if expiredMode != "warn" && expiredMode != "fail" {
+		return fmt.Errorf("unsupported --on-expired %q (use warn|fail)", expiredMode)
+	}
+	runnerName := config.Runner
+	if cmd.Flags().Changed("runner") {
+		runnerName = runnerFlag
+	}
+	runner, err := plan.ParseRunner(runnerName)
+	if err != nil {
+		return err
+	}
+	if parallelism < 1 {
+		return fmt.Errorf("--parallelism must be at least 1, got %d", parallelism)
+	}
+	if workspace != "" && allWorkspaces {
+		return fmt.Errorf("--workspace cannot be combined with --all-workspaces")
+	}
+
 	// Create cancellable context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Handle OS interrupts
+	// Handle OS interrupts (Ctrl-C) and SIGTERM (sent by CI systems). The
+	// runners are interrupted and get --grace-period to release state locks.
+	if gracePeriod < 0 {
+		return fmt.Errorf("--grace-period must not be negative")
+	}
+	plan.GracePeriod = gracePeriod
+	defer plan.KillProcesses()
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 	go func() {
-		<-sigChan
-		log.Warn("Received interrupt signal, initiating graceful shutdown...")
+		sig := <-sigChan
+		log.WithField("grace_period", gracePeriod).Warnf("Received %v, stopping the runners gracefully...", sig)
 		cancel()
+		// A second signal aborts immediately; deferred cleanup would not run.
+		sig = <-sigChan
+		log.Warnf("Received second %v, killing the runners and exiting", sig)
+		plan.KillProcesses()
+		plan.RemoveTempFiles()
+		if sig == syscall.SIGTERM {
+			os.Exit(143)
+		}
+		os.Exit(130)
 	}()
 
+	opts := drift.Options{
+		Path:           pathFlag,
+		Format:         formatFlag,
+		Strict:         strictFlag,
+		SensitivePaths: config.SensitiveAttributes,
+		ShowSensitive:  showSecrets,
+		IgnoreFile:     ignoreFile,
+		KeepPlan:       keepPlan,
+		Runner:         runner,
+		Workspace:      workspace,
+		DataSources:    dataSources,
+		IncludeOutputs: outputsFlag,
+	}
+	if err := applyPlanOptions(cmd, &opts, config.Plan); err != nil {
+		return err
+	}
+
+	if len(config.Components) > 0 && !cmd.Flags().Changed("path") {
+		return runComponents(ctx, opts)
+	}
+	if len(components) > 0 {
+		return fmt.Errorf("--component requires components in the config file")
+	}
+	if allWorkspaces {
+		// An unnamed component: its workspaces become the report sections.
+		return runAggregate(ctx, []drift.Component{{Options: opts}}, opts.KeepPlan)
+	}
+
 	log.WithFields(log.Fields{
 		"path":    pathFlag,
 		"format":  formatFlag,
@@ -70,26 +235,211 @@ func runScan(cmd *cobra.Command, args []string) error {
 		"timeout": timeout,
 	}).Info("Scan parameters")
 
-	res, err := drift.CheckDrift(ctx, drift.Options{
-		Path:   pathFlag,
-		Format: formatFlag,
-		Strict: strictFlag,
-	})
+	opts.Progress = newProgressLogger("")
+	opts.Retry.OnRetry = logRetry("")
+	res, err := drift.CheckDrift(ctx, opts)
 	if err != nil {
+		printFailureReport(err)
 		if ctx.Err() == context.DeadlineExceeded {
 			return fmt.Errorf("scan operation timed out after %v", timeout)
 		}
+		if errors.Is(err, plan.ErrNotInitialized) {
+			return fmt.Errorf("scan failed, %s is not initialized: %w", pathFlag, err)
+		}
 		return fmt.Errorf("scan failed: %w", err)
 	}
 
+	logWarnings(res)
+	logExpired(res)
+
 	// Print report (stdout). All other logs go to stderr.
 	fmt.Println(res.RenderedReport)
 
+	if expiredMode == "fail" && len(res.Expired) > 0 {
+		exitScan(2)
+	}
+
 	// Strict mode: exit 2 if drift
 	if strictFlag && res.DriftDetected {
 		// Using os.Exit(2) to conform to required contract
-		os.Exit(2)
+		exitScan(2)
+	}
+
+	return nil
+}
+
+// exitScan exits with code, first killing runners still in their grace
+// period: os.Exit skips the deferred plan.KillProcesses of runScan.
+func exitScan(code int) {
+	plan.KillProcesses()
+	os.Exit(code)
+}
+
+// runComponents scans every configured component and prints the aggregated report.
+func runComponents(ctx context.Context, base drift.Options) error {
+	comps, err := parseComponents(config.Components, base)
+	if err != nil {
+		return fmt.Errorf("invalid components config: %w", err)
+	}
+	if comps, err = selectComponents(comps, components); err != nil {
+		return err
+	}
+	if base.Workspace != "" {
+		for i := range comps {
+			comps[i].Options.Workspace = base.Workspace
+		}
+	}
+	return runAggregate(ctx, comps, base.KeepPlan)
+}
+
+// runAggregate scans comps (expanded per workspace with --all-workspaces) with
+// the worker pool and prints the aggregated report. keepDir, when set, receives
+// one <name>.tfplan per scan.
+func runAggregate(ctx context.Context, comps []drift.Component, keepDir string) error {
+	if allWorkspaces {
+		var err error
+		if comps, err = drift.ExpandWorkspaces(ctx, comps); err != nil {
+			return fmt.Errorf("failed to list workspaces: %w", err)
+		}
+		if len(comps) == 0 {
+			return fmt.Errorf("no workspaces found")
+		}
+	}
+	for i := range comps {
+		if keepDir != "" {
+			comps[i].Options.KeepPlan = filepath.Join(keepDir, filepath.FromSlash(comps[i].Name)+".tfplan")
+		}
+		comps[i].Options.Progress = newProgressLogger(comps[i].Name)
+		comps[i].Options.Retry.OnRetry = logRetry(comps[i].Name)
 	}
 
+	log.WithFields(log.Fields{
+		"components":        len(comps),
+		"all_workspaces":    allWorkspaces,
+		"format":            formatFlag,
+		"strict":            strictFlag,
+		"timeout":           timeout,
+		"parallelism":       parallelism,
+		"component_timeout": compTimeout,
+		"fail_fast":         failFast,
+	}).Info("Scan parameters")
+
+	agg, err := drift.CheckComponents(ctx, comps, formatFlag, report.Metadata{
+		Cloud:   config.Cloud,
+		Account: config.Account,
+		Region:  config.Region,
+		Labels:  config.Metadata,
+	}, drift.PoolOptions{
+		Parallelism: parallelism,
+		Timeout:     compTimeout,
+		FailFast:    failFast,
+	})
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+
+	expired := false
+	for _, c := range agg.Components {
+		if errors.Is(c.Err, drift.ErrSkipped) {
+			log.WithField("path", c.Path).Warnf("Scan skipped: %v", c.Err)
+			continue
+		}
+		if c.Err != nil {
+			log.WithField("path", c.Path).Errorf("Scan failed: %v", c.Err)
+			continue
+		}
+		log.WithFields(log.Fields{"path": c.Path, "duration": c.Duration.Round(time.Second)}).Debugf("Scanned component %s", c.Name)
+		logWarnings(c.Result)
+		logExpired(c.Result)
+		expired = expired || len(c.Result.Expired) > 0
+	}
+
+	fmt.Println(agg.RenderedReport)
+
+	if agg.Failed > 0 {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			return fmt.Errorf("scan operation timed out after %v", timeout)
+		case context.Canceled:
+			return fmt.Errorf("scan interrupted: %d of %d components not completed", agg.Failed, len(agg.Components))
+		}
+		return fmt.Errorf("%d of %d components failed to scan", agg.Failed, len(agg.Components))
+	}
+	if expiredMode == "fail" && expired {
+		exitScan(2)
+	}
+	if strictFlag && agg.DriftDetected {
+		exitScan(2)
+	}
 	return nil
 }
+
+// selectComponents keeps only the named components, in config order.
+func selectComponents(comps []drift.Component, names []string) ([]drift.Component, error) {
+	if len(names) == 0 {
+		return comps, nil
+	}
+	want := map[string]bool{}
+	for _, n := range names {
+		want[n] = true
+	}
+	var out []drift.Component
+	for _, c := range comps {
+		if want[c.Name] {
+			out = append(out, c)
+			delete(want, c.Name)
+		}
+	}
+	if len(want) > 0 {
+		missing := make([]string, 0, len(want))
+		for n := range want {
+			missing = append(missing, n)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("unknown component(s): %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// printFailureReport prints a report for a scan that failed in the runner, so
+// its diagnostics reach the report consumer (stdout) and not only the logs.
+func printFailureReport(err error) {
+	var re *plan.RunnerError
+	if !errors.As(err, &re) {
+		return
+	}
+	out, rerr := report.RenderFailure(formatFlag, err, pathFlag)
+	if rerr != nil || out == "" {
+		return
+	}
+	fmt.Println(out)
+}
+
+// logRetry returns a RetryPolicy.OnRetry callback logging the retries of the
+// scan named name.
+func logRetry(name string) func(int, time.Duration, error) {
+	return func(attempt int, wait time.Duration, err error) {
+		entry := log.WithFields(log.Fields{"attempt": attempt, "wait": wait, "category": plan.Category(err)})
+		if name != "" {
+			entry = entry.WithField("component", name)
+		}
+		msg, _, _ := strings.Cut(err.Error(), "\n")
+		entry.Warnf("Plan failed, retrying: %s", msg)
+	}
+}
+
+func logWarnings(res drift.Result) {
+	for _, w := range res.Warnings {
+		log.WithField("runner", res.RunnerVersion.String()).Warn(w)
+	}
+}
+
+func logExpired(res drift.Result) {
+	for _, e := range res.Expired {
+		log.WithFields(log.Fields{
+			"file":    res.IgnoreFile,
+			"expires": e.ExpiresRaw,
+			"reason":  e.Reason,
+		}).Warnf("Exemption has expired and is no longer applied: %s", e)
+	}
+}
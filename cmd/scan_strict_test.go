@@ -20,7 +20,7 @@ func TestStrictExitCode_OnDrift(t *testing.T) {
 		// ---- child process path ----
 		// Stub runner selection and plan JSON so we avoid calling real binaries.
 		drift.SelectRunner = func() (plan.RunnerKind, error) { return plan.RunnerTofu, nil }
-		drift.PlanJSON = func(_ context.Context, _ plan.RunnerKind, _ string) ([]byte, error) {
+		drift.PlanJSON = func(_ context.Context, _ plan.RunnerKind, _ string, _ plan.PlanOptions) ([]byte, error) {
 			// IMPORTANT: when running tests in the cmd package, the CWD is ./cmd,
 			// so we need to go up one directory to reach internal/plan/testdata.
 			fixture := filepath.Join("..", "internal", "plan", "testdata", "plan_drift.json")
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// applySensitivity masks sensitive attribute values (plan markers plus the
+// configured sensitive_attributes patterns) unless --show-sensitive was given.
+// The scan command does the same inside drift.CheckDrift.
+func applySensitivity(stats plan.Stats, show bool) plan.Stats {
+	if show {
+		return plan.RevealSensitive(stats)
+	}
+	return plan.MaskSensitive(stats, config.SensitiveAttributes)
+}
+
+// checkShowSensitive refuses --show-sensitive when stdout is not a terminal, so
+// secrets can never end up in CI logs, files or PR comments.
+func checkShowSensitive(show bool) error {
+	if show && !stdoutIsTerminal() {
+		return errors.New("--show-sensitive is only allowed when stdout is a terminal")
+	}
+	return nil
+}
+
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
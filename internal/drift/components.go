@@ -0,0 +1,166 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+	"github.com/ha36d/drift-checker/internal/report"
+)
+
+// Component is a named root module scanned with its own options.
+type Component struct {
+	Name    string
+	Options Options
+}
+
+// ComponentResult is the outcome of scanning one component.
+type ComponentResult struct {
+	Name     string
+	Path     string
+	Options  plan.PlanOptions // runner options the component was scanned with
+	Result   Result
+	Err      error
+	Duration time.Duration
+}
+
+// PoolOptions controls how CheckComponents schedules the component scans.
+type PoolOptions struct {
+	Parallelism int           // maximum number of concurrent scans; <= 0 means 1
+	Timeout     time.Duration // per-component timeout; 0 means only the parent context applies
+	FailFast    bool          // cancel the remaining scans after the first failure
+}
+
+// Aggregate is the combined outcome of scanning several components.
+type Aggregate struct {
+	Components     []ComponentResult // in the order the components were given
+	RenderedReport string
+	DriftDetected  bool // at least one component drifted
+	Failed         int  // number of components whose scan failed or was skipped
+}
+
+// ErrSkipped marks components that were not scanned (or were cancelled)
+// because another component failed in fail-fast mode.
+var ErrSkipped = errors.New("skipped after an earlier failure (fail-fast)")
+
+// CheckComponents scans the components with a worker pool of at most
+// pool.Parallelism concurrent scans and renders one aggregated report in the
+// given format. Results are reported in the order of components regardless of
+// completion order. Unless pool.FailFast is set, a failing component does not
+// stop the others; its error is reported in its own section and counted in
+// Aggregate.Failed. Cancelling ctx stops all running scans.
+func CheckComponents(ctx context.Context, components []Component, format string, meta report.Metadata, pool PoolOptions) (Aggregate, error) {
+	if err := report.ValidateFormat(format); err != nil {
+		return Aggregate{}, err
+	}
+
+	agg := Aggregate{Components: runPool(ctx, components, pool)}
+	return agg.render(format, meta)
+}
+
+func runPool(ctx context.Context, components []Component, pool PoolOptions) []ComponentResult {
+	workers := pool.Parallelism
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(components) {
+		workers = len(components)
+	}
+
+	ctx, abort := context.WithCancel(ctx)
+	defer abort()
+
+	results := make([]ComponentResult, len(components))
+	jobs := make(chan int)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		aborted bool
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c := components[i]
+				start := time.Now()
+				res, err := scanComponent(ctx, c, pool.Timeout)
+				mu.Lock()
+				if err != nil && aborted {
+					err = fmt.Errorf("component %s: %w", c.Name, ErrSkipped)
+				}
+				if err != nil && pool.FailFast && !aborted {
+					aborted = true
+					abort()
+				}
+				mu.Unlock()
+				results[i] = ComponentResult{Name: c.Name, Path: c.Options.Path, Options: c.Options.PlanOptions(), Result: res, Err: err, Duration: time.Since(start)}
+			}
+		}()
+	}
+
+	for i, c := range components {
+		mu.Lock()
+		stop := aborted
+		mu.Unlock()
+		if stop || ctx.Err() != nil {
+			err := ErrSkipped
+			if !stop {
+				err = ctx.Err()
+			}
+			results[i] = ComponentResult{Name: c.Name, Path: c.Options.Path, Options: c.Options.PlanOptions(), Err: fmt.Errorf("component %s: %w", c.Name, err)}
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// scanComponent scans one component, bounded by timeout when set.
+func scanComponent(ctx context.Context, c Component, timeout time.Duration) (Result, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	res, err := scan(ctx, c.Options)
+	if err == nil {
+		return res, nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && timeout > 0 {
+		err = fmt.Errorf("timed out after %v: %w", timeout, err)
+	}
+	return Result{}, fmt.Errorf("component %s: %w", c.Name, err)
+}
+
+func (agg Aggregate) render(format string, meta report.Metadata) (Aggregate, error) {
+	sections := make([]report.Section, 0, len(agg.Components))
+	agg.DriftDetected, agg.Failed = false, 0
+	for _, c := range agg.Components {
+		if c.Err != nil {
+			agg.Failed++
+		} else if c.Result.DriftDetected {
+			agg.DriftDetected = true
+		}
+		sections = append(sections, report.Section{
+			Name:    c.Name,
+			Path:    c.Path,
+			Runner:  c.Result.RunnerVersion,
+			Options: c.Options,
+			Stats:   c.Result.Stats,
+			Err:     c.Err,
+		})
+	}
+
+	rendered, err := report.RenderAggregate(format, meta, sections)
+	if err != nil {
+		return Aggregate{}, fmt.Errorf("failed to render %s: %w", format, err)
+	}
+	agg.RenderedReport = rendered
+	return agg, nil
+}
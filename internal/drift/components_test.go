@@ -0,0 +1,189 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+	"github.com/ha36d/drift-checker/internal/report"
+)
+
+func stubPlanJSON(t *testing.T, fn func(ctx context.Context, path string, opts plan.PlanOptions) ([]byte, error)) {
+	t.Helper()
+	orig := PlanJSON
+	PlanJSON = func(ctx context.Context, _ plan.RunnerKind, path string, opts plan.PlanOptions) ([]byte, error) {
+		return fn(ctx, path, opts)
+	}
+	origVersion := DetectVersion
+	DetectVersion = func(_ context.Context, r plan.RunnerKind, _ string, _ plan.PlanOptions) (plan.RunnerVersion, error) {
+		return plan.RunnerVersion{Kind: r, Version: "1.8.2"}, nil
+	}
+	t.Cleanup(func() { PlanJSON, DetectVersion = orig, origVersion })
+}
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("..", "plan", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func testComponents(names ...string) []Component {
+	comps := make([]Component, 0, len(names))
+	for _, n := range names {
+		comps = append(comps, Component{Name: n, Options: Options{Path: n, Runner: plan.RunnerTofu}})
+	}
+	return comps
+}
+
+func TestCheckComponents_ParallelDeterministicOrder(t *testing.T) {
+	clean, drifted := fixture(t, "plan_clean.json"), fixture(t, "plan_drift.json")
+	delays := map[string]time.Duration{"a": 60 * time.Millisecond, "b": 30 * time.Millisecond, "c": 0, "d": 10 * time.Millisecond}
+
+	var running, peak int32
+	stubPlanJSON(t, func(ctx context.Context, path string, _ plan.PlanOptions) ([]byte, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(delays[filepath.Base(path)])
+		if filepath.Base(path) == "c" {
+			return drifted, nil
+		}
+		return clean, nil
+	})
+
+	agg, err := CheckComponents(context.Background(), testComponents("a", "b", "c", "d"), "json", report.Metadata{}, PoolOptions{Parallelism: 2})
+	if err != nil {
+		t.Fatalf("CheckComponents error: %v", err)
+	}
+	for i, want := range []string{"a", "b", "c", "d"} {
+		if agg.Components[i].Name != want {
+			t.Fatalf("component %d = %q, want %q", i, agg.Components[i].Name, want)
+		}
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent scans, got %d", peak)
+	}
+	if !agg.DriftDetected || agg.Failed != 0 || !agg.Components[2].Result.DriftDetected {
+		t.Fatalf("unexpected aggregate: drift=%v failed=%d", agg.DriftDetected, agg.Failed)
+	}
+}
+
+func TestCheckComponents_FailFast(t *testing.T) {
+	clean := fixture(t, "plan_clean.json")
+	boom := errors.New("boom")
+	stubPlanJSON(t, func(ctx context.Context, path string, _ plan.PlanOptions) ([]byte, error) {
+		switch filepath.Base(path) {
+		case "a":
+			return nil, boom
+		case "b":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return clean, nil
+	})
+
+	comps := testComponents("a", "b", "c")
+	agg, err := CheckComponents(context.Background(), comps, "md", report.Metadata{}, PoolOptions{Parallelism: 2, FailFast: true})
+	if err != nil {
+		t.Fatalf("CheckComponents error: %v", err)
+	}
+	if !errors.Is(agg.Components[0].Err, boom) {
+		t.Fatalf("expected first component to fail with boom, got %v", agg.Components[0].Err)
+	}
+	for _, c := range agg.Components[1:] {
+		if !errors.Is(c.Err, ErrSkipped) {
+			t.Fatalf("expected %s to be skipped, got %v", c.Name, c.Err)
+		}
+	}
+	if agg.Failed != 3 {
+		t.Fatalf("expected 3 failed components, got %d", agg.Failed)
+	}
+
+	// Without fail-fast the other components still complete.
+	stubPlanJSON(t, func(ctx context.Context, path string, _ plan.PlanOptions) ([]byte, error) {
+		if filepath.Base(path) == "a" {
+			return nil, boom
+		}
+		return clean, nil
+	})
+	agg, err = CheckComponents(context.Background(), comps, "md", report.Metadata{}, PoolOptions{Parallelism: 2})
+	if err != nil {
+		t.Fatalf("CheckComponents error: %v", err)
+	}
+	if agg.Failed != 1 || agg.Components[1].Err != nil || agg.Components[2].Err != nil {
+		t.Fatalf("expected only the first component to fail, got %+v", agg.Components)
+	}
+}
+
+func TestCheckComponents_Timeout(t *testing.T) {
+	stubPlanJSON(t, func(ctx context.Context, _ string, _ plan.PlanOptions) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	agg, err := CheckComponents(context.Background(), testComponents("slow"), "text", report.Metadata{}, PoolOptions{Timeout: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("CheckComponents error: %v", err)
+	}
+	if err := agg.Components[0].Err; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestCheckComponents_RetryTransient(t *testing.T) {
+	clean := fixture(t, "plan_clean.json")
+	var calls atomic.Int32
+	stubPlanJSON(t, func(_ context.Context, _ string, _ plan.PlanOptions) ([]byte, error) {
+		if calls.Add(1) == 1 {
+			return nil, &plan.RunnerError{Runner: plan.RunnerTofu, Op: "plan", Category: plan.CategoryLocked, Err: errors.New("exit status 1")}
+		}
+		return clean, nil
+	})
+
+	comps := testComponents("net")
+	comps[0].Options.Retry = plan.RetryPolicy{Retries: 1, Delay: time.Millisecond}
+	agg, err := CheckComponents(context.Background(), comps, "json", report.Metadata{}, PoolOptions{Parallelism: 1})
+	if err != nil {
+		t.Fatalf("CheckComponents error: %v", err)
+	}
+	if agg.Failed != 0 || calls.Load() != 2 {
+		t.Fatalf("locked plan not retried: failed=%d calls=%d", agg.Failed, calls.Load())
+	}
+}
+
+func TestCheckDrift_IncludeOutputs(t *testing.T) {
+	outputs := fixture(t, "plan_outputs.json")
+	stubPlanJSON(t, func(_ context.Context, _ string, _ plan.PlanOptions) ([]byte, error) {
+		return outputs, nil
+	})
+
+	opts := Options{Path: t.TempDir(), Format: "json", Runner: plan.RunnerTofu}
+	res, err := CheckDrift(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("CheckDrift error: %v", err)
+	}
+	if res.Stats.Outputs != nil || res.DriftDetected {
+		t.Fatalf("outputs reported without IncludeOutputs: %+v", res.Stats.Outputs)
+	}
+
+	opts.IncludeOutputs = true
+	if res, err = CheckDrift(context.Background(), opts); err != nil {
+		t.Fatalf("CheckDrift error: %v", err)
+	}
+	if len(res.Stats.Outputs) != 3 || res.DriftDetected {
+		t.Fatalf("unexpected result with IncludeOutputs: drift=%v outputs=%+v", res.DriftDetected, res.Stats.Outputs)
+	}
+}
@@ -3,15 +3,54 @@ package drift
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/ha36d/drift-checker/internal/ignore"
 	"github.com/ha36d/drift-checker/internal/plan"
 	"github.com/ha36d/drift-checker/internal/report"
 )
 
 type Options struct {
-	Path   string // working dir
-	Format string // "md" | "text" | "json"
-	Strict bool
+	Path           string // working dir
+	Format         string // "md" | "text" | "json" | "sarif" | "junit"
+	Strict         bool
+	SensitivePaths []string                 // attribute path globs whose values are masked
+	ShowSensitive  bool                     // render sensitive values as-is (interactive use only)
+	IgnoreFile     string                   // exemption file; defaults to .driftignore.yaml in Path when empty
+	Runner         plan.RunnerKind          // empty selects automatically (Terragrunt, then tofu, then terraform)
+	Workspace      string                   // selected via TF_WORKSPACE
+	VarFiles       []string                 // -var-file arguments, relative to Path
+	Vars           []string                 // -var arguments ("name=value")
+	Targets        []string                 // -target arguments
+	NoLock         bool                     // -lock=false
+	LockTimeout    time.Duration            // -lock-timeout; 0 uses the runner default
+	Parallelism    int                      // the runner's -parallelism; 0 uses the runner default
+	Init           bool                     // run init -input=false before the plan
+	BackendConfig  []string                 // -backend-config arguments of init
+	Env            []string                 // extra KEY=VALUE environment entries for the runner
+	KeepPlan       string                   // persist the binary refresh-only plan at this path
+	Progress       func(plan.ProgressEvent) // receives the plan's progress messages while it runs
+	Retry          plan.RetryPolicy         // retries of a plan that failed for a transient reason
+	DataSources    bool                     // report data sources whose read result changed (Stats.DataChanges)
+	IncludeOutputs bool                     // report root module outputs whose value would change (Stats.Outputs)
+}
+
+// PlanOptions returns the runner options of the refresh-only plan.
+func (o Options) PlanOptions() plan.PlanOptions {
+	return plan.PlanOptions{
+		VarFiles:      o.VarFiles,
+		Vars:          o.Vars,
+		Targets:       o.Targets,
+		NoLock:        o.NoLock,
+		LockTimeout:   o.LockTimeout,
+		Parallelism:   o.Parallelism,
+		Init:          o.Init,
+		BackendConfig: o.BackendConfig,
+		Workspace:     o.Workspace,
+		Env:           o.Env,
+		KeepPlan:      o.KeepPlan,
+		Progress:      o.Progress,
+	}
 }
 
 type Result struct {
@@ -19,21 +58,64 @@ type Result struct {
 	RenderedReport string
 	DriftDetected  bool
 	Runner         plan.RunnerKind
+	RunnerVersion  plan.RunnerVersion // detected runner version (Version is empty when detection failed)
+	Warnings       []string           // compatibility warnings (runner version, plan format)
+	IgnoreFile     string             // exemption file that was applied, if any
+	Expired        []ignore.Exemption // exemptions past their expiry date (not applied)
 }
 
 // Test hooks (overridable in tests)
 var (
-	SelectRunner = plan.SelectRunner
-	PlanJSON     = plan.MakeRefreshOnlyPlanJSON
+	SelectRunner  = plan.SelectRunner
+	PlanJSON      = plan.MakeRefreshOnlyPlanJSON
+	DetectVersion = plan.DetectVersion
 )
 
 func CheckDrift(ctx context.Context, opts Options) (Result, error) {
-	runner, err := SelectRunner()
+	if err := report.ValidateFormat(opts.Format); err != nil {
+		return Result{}, err
+	}
+
+	res, err := scan(ctx, opts)
 	if err != nil {
 		return Result{}, err
 	}
 
-	planJSON, err := PlanJSON(ctx, runner, opts.Path)
+	res.RenderedReport, err = report.Render(opts.Format, res.Stats, res.RunnerVersion, opts.PlanOptions(), opts.Path)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to render %s: %w", opts.Format, err)
+	}
+	return res, nil
+}
+
+// scan runs the refresh-only plan for one working directory and returns the
+// parsed, masked and filtered result without rendering it.
+func scan(ctx context.Context, opts Options) (Result, error) {
+	// Load exemptions first so an invalid file fails before the (slow) plan runs.
+	exemptions, err := loadExemptions(opts)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to load ignore file: %w", err)
+	}
+
+	runner := opts.Runner
+	if runner == "" {
+		if runner, err = plan.SelectRunnerFor(opts.Path, SelectRunner); err != nil {
+			return Result{}, err
+		}
+	}
+
+	var warnings []string
+	version, err := DetectVersion(ctx, runner, opts.Path, opts.PlanOptions())
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("could not detect %s version: %v", runner, err))
+		version = plan.RunnerVersion{Kind: runner}
+	}
+
+	var planJSON []byte
+	err = opts.Retry.Do(ctx, func() (err error) {
+		planJSON, err = PlanJSON(ctx, runner, opts.Path, opts.PlanOptions())
+		return err
+	})
 	if err != nil {
 		return Result{}, err
 	}
@@ -42,26 +124,35 @@ func CheckDrift(ctx context.Context, opts Options) (Result, error) {
 	if err != nil {
 		return Result{}, fmt.Errorf("failed to parse plan JSON: %w", err)
 	}
-
-	var rendered string
-	switch opts.Format {
-	case "", "md", "markdown":
-		rendered = report.RenderMarkdown(stats, runner)
-	case "text", "txt":
-		rendered = report.RenderText(stats, runner)
-	case "json":
-		rendered, err = report.RenderJSON(stats)
-		if err != nil {
-			return Result{}, fmt.Errorf("failed to render json: %w", err)
-		}
-	default:
-		return Result{}, fmt.Errorf("unsupported format %q (use md|text|json)", opts.Format)
+	warnings = append(warnings, stats.Warnings...)
+	warnings = append(warnings, plan.CheckRunnerVersion(stats, version)...)
+	if opts.ShowSensitive {
+		stats = plan.RevealSensitive(stats)
+	} else {
+		stats = plan.MaskSensitive(stats, opts.SensitivePaths)
+	}
+	if !opts.DataSources {
+		stats.DataChanges = nil
+	}
+	if !opts.IncludeOutputs {
+		stats.Outputs = nil
 	}
+	stats, expired := exemptions.Apply(stats, time.Now())
 
 	return Result{
-		Stats:          stats,
-		RenderedReport: rendered,
-		DriftDetected:  stats.Updates > 0 || stats.Deletes > 0 || stats.Replaces > 0,
-		Runner:         runner,
+		Stats:         stats,
+		DriftDetected: len(plan.DriftedAddresses(stats)) > 0,
+		Runner:        runner,
+		RunnerVersion: version,
+		Warnings:      warnings,
+		IgnoreFile:    exemptions.Name(),
+		Expired:       expired,
 	}, nil
 }
+
+func loadExemptions(opts Options) (*ignore.File, error) {
+	if opts.IgnoreFile != "" {
+		return ignore.Load(opts.IgnoreFile)
+	}
+	return ignore.Find(opts.Path)
+}
@@ -0,0 +1,48 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// ListWorkspaces is a test hook (overridable in tests).
+var ListWorkspaces = plan.ListWorkspaces
+
+// ExpandWorkspaces replaces every component with one component per workspace
+// of its working directory. The workspaces are selected with TF_WORKSPACE, so
+// the directory's selected workspace is never changed. Expanded components are
+// named "<name>/<workspace>", or just the workspace when name is empty.
+func ExpandWorkspaces(ctx context.Context, components []Component) ([]Component, error) {
+	var out []Component
+	for _, c := range components {
+		runner := c.Options.Runner
+		if runner == "" {
+			var err error
+			if runner, err = plan.SelectRunnerFor(c.Options.Path, SelectRunner); err != nil {
+				return nil, err
+			}
+		}
+
+		workspaces, err := ListWorkspaces(ctx, runner, c.Options.Path, c.Options.PlanOptions())
+		if err != nil {
+			label := c.Name
+			if label == "" {
+				label = c.Options.Path
+			}
+			return nil, fmt.Errorf("component %s: %w", label, err)
+		}
+		for _, ws := range workspaces {
+			wc := c
+			wc.Name = ws
+			if c.Name != "" {
+				wc.Name = c.Name + "/" + ws
+			}
+			wc.Options.Runner = runner
+			wc.Options.Workspace = ws
+			out = append(out, wc)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package drift
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+	"github.com/ha36d/drift-checker/internal/report"
+)
+
+func TestExpandWorkspaces(t *testing.T) {
+	orig := ListWorkspaces
+	ListWorkspaces = func(_ context.Context, _ plan.RunnerKind, path string, _ plan.PlanOptions) ([]string, error) {
+		return []string{"default", "prod"}, nil
+	}
+	defer func() { ListWorkspaces = orig }()
+
+	clean, drifted := fixture(t, "plan_clean.json"), fixture(t, "plan_drift.json")
+	var mu sync.Mutex
+	seen := map[string]string{}
+	stubPlanJSON(t, func(_ context.Context, path string, opts plan.PlanOptions) ([]byte, error) {
+		mu.Lock()
+		seen[opts.Workspace] = path
+		mu.Unlock()
+		if opts.Workspace == "prod" {
+			return drifted, nil
+		}
+		return clean, nil
+	})
+
+	comps, err := ExpandWorkspaces(context.Background(), []Component{
+		{Options: Options{Path: "infra", Runner: plan.RunnerTofu}},
+		{Name: "dns", Options: Options{Path: "dns", Runner: plan.RunnerTofu}},
+	})
+	if err != nil {
+		t.Fatalf("ExpandWorkspaces error: %v", err)
+	}
+	var names []string
+	for _, c := range comps {
+		names = append(names, c.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"default", "prod", "dns/default", "dns/prod"}) {
+		t.Fatalf("unexpected components: %v", names)
+	}
+
+	agg, err := CheckComponents(context.Background(), comps[:2], "json", report.Metadata{}, PoolOptions{Parallelism: 2})
+	if err != nil {
+		t.Fatalf("CheckComponents error: %v", err)
+	}
+	if seen["default"] != "infra" || seen["prod"] != "infra" {
+		t.Fatalf("workspaces not selected per scan: %v", seen)
+	}
+	if agg.Components[0].Result.DriftDetected || !agg.Components[1].Result.DriftDetected || !agg.DriftDetected {
+		t.Fatalf("expected drift only in prod: %+v", agg.Components)
+	}
+}
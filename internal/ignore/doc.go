@@ -0,0 +1,2 @@
+// Package ignore loads drift exemption files (.driftignore.yaml) and applies them to parsed plan stats.
+package ignore
@@ -0,0 +1,227 @@
+package ignore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// DefaultFileNames are looked up (in order) in the scanned working directory
+// when no explicit --ignore-file is given.
+var DefaultFileNames = []string{".driftignore.yaml", ".driftignore.yml", ".driftignore"}
+
+const dateLayout = "2006-01-02"
+
+// File is a parsed exemption file.
+//
+//	exemptions:
+//	  - address: aws_autoscaling_group.*
+//	    attributes: [desired_capacity]
+//	    reason: capacity is managed by autoscaling
+//	    expires: 2026-12-31
+//	  - address: aws_s3_bucket.legacy
+//	    actions: [delete]
+//	    reason: bucket is being migrated
+type File struct {
+	Path       string      `yaml:"-"`
+	Exemptions []Exemption `yaml:"exemptions"`
+}
+
+// Exemption suppresses drift on the resources (and optionally attributes) it matches.
+// All selectors that are set must match. Deletes and replaces are only
+// suppressed by an exemption that lists their action.
+type Exemption struct {
+	Address    string    `yaml:"address"`    // resource address glob
+	Type       string    `yaml:"type"`       // resource type glob
+	Module     string    `yaml:"module"`     // module address glob; also covers instances and nested modules
+	Actions    []string  `yaml:"actions"`    // update|delete|replace; empty matches updates only
+	Attributes []string  `yaml:"attributes"` // attribute path globs; empty suppresses the whole resource
+	Reason     string    `yaml:"reason"`
+	ExpiresRaw string    `yaml:"expires"` // YYYY-MM-DD, inclusive
+	Expires    time.Time `yaml:"-"`
+}
+
+// Load reads and validates an exemption file. Unknown keys are errors: a
+// misspelled selector would otherwise widen its exemption.
+func Load(path string) (*File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Path: path}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for i := range f.Exemptions {
+		if err := f.Exemptions[i].validate(); err != nil {
+			return nil, fmt.Errorf("%s: exemption #%d: %w", path, i+1, err)
+		}
+	}
+	return f, nil
+}
+
+// Find loads the first default exemption file present in dir.
+// It returns (nil, nil) when there is none.
+func Find(dir string) (*File, error) {
+	for _, name := range DefaultFileNames {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return Load(p)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// Name returns the file path, or "" for a nil file.
+func (f *File) Name() string {
+	if f == nil {
+		return ""
+	}
+	return f.Path
+}
+
+func (e *Exemption) validate() error {
+	if strings.TrimSpace(e.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	if e.Address == "" && e.Type == "" && e.Module == "" && len(e.Attributes) == 0 {
+		return errors.New("at least one of address, type, module or attributes is required")
+	}
+	for _, a := range e.Actions {
+		if !slices.Contains(exemptActions, plan.Action(a)) {
+			return fmt.Errorf("invalid action %q (use update|delete|replace)", a)
+		}
+	}
+	if e.ExpiresRaw != "" {
+		t, err := time.Parse(dateLayout, e.ExpiresRaw)
+		if err != nil {
+			return fmt.Errorf("invalid expires %q (use YYYY-MM-DD): %w", e.ExpiresRaw, err)
+		}
+		e.Expires = t
+	}
+	return nil
+}
+
+// Expired reports whether the exemption is past its expiry date at now.
+// The expiry date itself is still valid, until midnight in now's time zone.
+func (e Exemption) Expired(now time.Time) bool {
+	if e.Expires.IsZero() {
+		return false
+	}
+	y, m, d := e.Expires.Date()
+	return !now.Before(time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()))
+}
+
+// String describes the exemption's selectors for logs and error messages.
+func (e Exemption) String() string {
+	var parts []string
+	if e.Address != "" {
+		parts = append(parts, "address="+e.Address)
+	}
+	if e.Type != "" {
+		parts = append(parts, "type="+e.Type)
+	}
+	if e.Module != "" {
+		parts = append(parts, "module="+e.Module)
+	}
+	if len(e.Attributes) > 0 {
+		parts = append(parts, "attributes="+strings.Join(e.Attributes, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// exemptActions are the drift actions an exemption can list.
+var exemptActions = []plan.Action{plan.ActionUpdate, plan.ActionDelete, plan.ActionReplace}
+
+func (e Exemption) matchesResource(c plan.Change) bool {
+	action := c.Action()
+	if len(e.Actions) > 0 {
+		if !slices.Contains(e.Actions, string(action)) {
+			return false
+		}
+	} else if action == plan.ActionDelete || action == plan.ActionReplace {
+		return false
+	}
+	if e.Address != "" && !plan.MatchGlob(e.Address, c.Address) {
+		return false
+	}
+	if e.Type != "" && !plan.MatchGlob(e.Type, c.Type) {
+		return false
+	}
+	if e.Module != "" && !plan.MatchModule(e.Module, c.ModuleAddress) {
+		return false
+	}
+	return true
+}
+
+func (e Exemption) matchesAttribute(path string) bool {
+	return slices.ContainsFunc(e.Attributes, func(p string) bool {
+		return plan.MatchGlob(p, path) || plan.MatchGlob(p+".*", path) || plan.MatchGlob(p+"[*", path)
+	})
+}
+
+// Apply removes exempted drift from s and records it in s.Suppressed.
+// Expired exemptions are not applied; they are returned so callers can warn or fail.
+func (f *File) Apply(s plan.Stats, now time.Time) (plan.Stats, []Exemption) {
+	if f == nil {
+		return s, nil
+	}
+	var active, expired []Exemption
+	for _, e := range f.Exemptions {
+		if e.Expired(now) {
+			expired = append(expired, e)
+		} else {
+			active = append(active, e)
+		}
+	}
+
+	s = plan.Filter(s, func(c plan.Change, external bool) (plan.Change, bool, []plan.Suppressed) {
+		var sup []plan.Suppressed
+		for _, e := range active {
+			if !e.matchesResource(c) {
+				continue
+			}
+			if len(e.Attributes) == 0 {
+				sup = append(sup, plan.Suppressed{Address: c.Address, External: external, Reason: e.Reason, Expires: e.Expires})
+				return c, false, sup
+			}
+			// Attribute-scoped exemptions only apply to changes with an attribute diff.
+			if len(c.Attributes) == 0 {
+				continue
+			}
+			var kept []plan.AttributeChange
+			var paths []string
+			for _, a := range c.Attributes {
+				if e.matchesAttribute(a.Path) {
+					paths = append(paths, a.Path)
+				} else {
+					kept = append(kept, a)
+				}
+			}
+			if len(paths) == 0 {
+				continue
+			}
+			sup = append(sup, plan.Suppressed{Address: c.Address, External: external, Attributes: paths, Reason: e.Reason, Expires: e.Expires})
+			c.Attributes = kept
+			if len(kept) == 0 {
+				return c, false, sup
+			}
+		}
+		return c, true, sup
+	})
+	return s, expired
+}
@@ -0,0 +1,145 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestLoad_Validation(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"missing reason":  "exemptions:\n  - address: aws_instance.web\n",
+		"no selector":     "exemptions:\n  - reason: because\n",
+		"invalid expires": "exemptions:\n  - address: a.b\n    reason: r\n    expires: 31/12/2030\n",
+		"unknown key":     "exemptions:\n  - address: a.b\n    atributes: [tags]\n    reason: r\n",
+		"invalid action":  "exemptions:\n  - address: a.b\n    actions: [destroy]\n    reason: r\n",
+	}
+	for name, content := range cases {
+		p := filepath.Join(dir, strings.ReplaceAll(name, " ", "_")+".yaml")
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(p); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestFind_NoFile(t *testing.T) {
+	f, err := Find(t.TempDir())
+	if err != nil || f != nil {
+		t.Fatalf("expected (nil, nil), got (%v, %v)", f, err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	f, err := Load(filepath.Join("testdata", "driftignore.yaml"))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	s := plan.Stats{
+		Updates:          3,
+		Deletes:          1,
+		DriftedResources: []string{"aws_autoscaling_group.web", "module.network.module.sg.aws_security_group.app", "aws_instance.web", "aws_s3_bucket.legacy"},
+		Changes: []plan.Change{
+			{
+				Address: "aws_autoscaling_group.web", Type: "aws_autoscaling_group", Actions: []string{"update"},
+				Attributes: []plan.AttributeChange{{Path: "desired_capacity", Kind: plan.DiffChanged, Before: 2, After: 5}},
+			},
+			{
+				Address: "module.network.module.sg.aws_security_group.app", ModuleAddress: "module.network.module.sg",
+				Type: "aws_security_group", Actions: []string{"update"},
+			},
+			{
+				Address: "aws_instance.web", Type: "aws_instance", Actions: []string{"update"},
+				Attributes: []plan.AttributeChange{
+					{Path: "tags.SecurityScanDate", Kind: plan.DiffAdded, After: "2026-10-01"},
+					{Path: "instance_type", Kind: plan.DiffChanged, Before: "t3.micro", After: "t3.large"},
+				},
+			},
+			{Address: "aws_s3_bucket.legacy", Type: "aws_s3_bucket", Actions: []string{"delete"}},
+		},
+	}
+
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	out, expired := f.Apply(s, now)
+
+	if len(expired) != 1 || expired[0].Address != "aws_s3_bucket.legacy" {
+		t.Fatalf("expected the legacy bucket exemption to be expired, got %v", expired)
+	}
+	if out.Updates != 1 || out.Deletes != 1 {
+		t.Fatalf("unexpected counts after suppression: %+v", out)
+	}
+	if got := strings.Join(out.DriftedResources, ","); got != "aws_instance.web,aws_s3_bucket.legacy" {
+		t.Fatalf("unexpected drifted resources: %s", got)
+	}
+	web, _ := out.ChangeFor("aws_instance.web")
+	if len(web.Attributes) != 1 || web.Attributes[0].Path != "instance_type" {
+		t.Fatalf("expected only instance_type to remain, got %+v", web.Attributes)
+	}
+	if len(out.Suppressed) != 3 {
+		t.Fatalf("expected 3 suppressed entries, got %+v", out.Suppressed)
+	}
+	if sup := out.Suppressed[2]; sup.Address != "aws_instance.web" || len(sup.Attributes) != 1 {
+		t.Fatalf("unexpected attribute suppression: %+v", sup)
+	}
+}
+
+func TestApply_DestructiveNeedsAction(t *testing.T) {
+	replace := plan.Change{
+		Address: "module.app[0].aws_instance.web", ModuleAddress: "module.app[0]", Type: "aws_instance", Actions: []string{"delete", "create"},
+		Attributes: []plan.AttributeChange{{Path: "ami", Kind: plan.DiffChanged, Before: "ami-1", After: "ami-2"}},
+	}
+	s := plan.Stats{Replaces: 1, DeleteBeforeCreate: 1, DriftedResources: []string{replace.Address}, Changes: []plan.Change{replace}}
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	for name, c := range map[string]struct {
+		e          Exemption
+		suppressed bool
+	}{
+		"attributes only":    {Exemption{Attributes: []string{"ami"}, Reason: "r"}, false},
+		"module only":        {Exemption{Module: "module.app", Reason: "r"}, false},
+		"other action":       {Exemption{Module: "module.app", Actions: []string{"update", "delete"}, Reason: "r"}, false},
+		"replace by module":  {Exemption{Module: "module.app", Actions: []string{"replace"}, Reason: "r"}, true},
+		"replace, attribute": {Exemption{Address: `module.app[0].aws_instance.*`, Actions: []string{"replace"}, Attributes: []string{"ami"}, Reason: "r"}, true},
+	} {
+		f := &File{Exemptions: []Exemption{c.e}}
+		out, _ := f.Apply(s, now)
+		if got := out.Replaces == 0; got != c.suppressed {
+			t.Errorf("%s: suppressed=%v, want %v (%+v)", name, got, c.suppressed, out)
+		}
+	}
+
+	// Module exemptions cover count and for_each instances.
+	e := Exemption{Module: "module.app", Reason: "r"}
+	for _, mod := range []string{"module.app[0]", `module.app["eu"]`, `module.app["eu"].module.sg`} {
+		if !e.matchesResource(plan.Change{ModuleAddress: mod, Actions: []string{"update"}}) {
+			t.Errorf("module exemption does not match %s", mod)
+		}
+	}
+}
+
+func TestExpired_IsInclusive(t *testing.T) {
+	e := Exemption{Expires: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)}
+	if e.Expired(time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC)) {
+		t.Fatalf("exemption must still apply on its expiry date")
+	}
+	if !e.Expired(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("exemption must expire the day after its expiry date")
+	}
+
+	// The date is a calendar day of the caller's time zone, not of UTC.
+	east, west := time.FixedZone("UTC+10", 10*3600), time.FixedZone("UTC-8", -8*3600)
+	if !e.Expired(time.Date(2026, 10, 18, 5, 0, 0, 0, east)) {
+		t.Fatalf("exemption must expire the day after its expiry date east of UTC")
+	}
+	if e.Expired(time.Date(2026, 10, 17, 20, 0, 0, 0, west)) {
+		t.Fatalf("exemption must still apply on its expiry date west of UTC")
+	}
+}
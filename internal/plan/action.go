@@ -0,0 +1,85 @@
+package plan
+
+import "slices"
+
+// Action is the normalized kind of a resource change.
+type Action string
+
+const (
+	ActionNoOp    Action = "no-op"
+	ActionCreate  Action = "create"
+	ActionRead    Action = "read"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionReplace Action = "replace"
+	ActionForget  Action = "forget" // removed from state without destroying (OpenTofu/Terraform "removed" blocks)
+)
+
+// Resource modes (the plan's mode): managed resources and data sources.
+const (
+	ModeManaged = "managed"
+	ModeData    = "data"
+)
+
+// ClassifyActions maps a plan actions list to a single Action:
+//   - ["create","delete"] or ["delete","create"] => replace
+//   - ["create"], ["read"], ["update"], ["delete"], ["forget"] => themselves
+//   - anything else (["no-op"], empty) => no-op
+func ClassifyActions(acts []string) Action {
+	if slices.Contains(acts, "create") && slices.Contains(acts, "delete") {
+		return ActionReplace
+	}
+	if len(acts) == 1 {
+		switch a := Action(acts[0]); a {
+		case ActionCreate, ActionRead, ActionUpdate, ActionDelete, ActionForget:
+			return a
+		}
+	}
+	return ActionNoOp
+}
+
+// Action returns the normalized action of the change.
+func (c Change) Action() Action {
+	return ClassifyActions(c.Actions)
+}
+
+// IsData reports whether c is a data source rather than a managed resource.
+func (c Change) IsData() bool {
+	return c.Mode == ModeData
+}
+
+// Moved reports whether the resource moved from PreviousAddress (a "moved"
+// block or a refactor), independently of its action.
+func (c Change) Moved() bool {
+	return c.PreviousAddress != "" && c.PreviousAddress != c.Address
+}
+
+// Imported reports whether the change imports an existing object ("import"
+// block), independently of its action.
+func (c Change) Imported() bool {
+	return c.Importing != nil
+}
+
+// CreateBeforeDestroy reports whether c is a replace that creates the new
+// object before destroying the old one (["create","delete"], from
+// lifecycle.create_before_destroy).
+func (c Change) CreateBeforeDestroy() bool {
+	return c.Action() == ActionReplace && c.Actions[0] == "create"
+}
+
+// IsDeposed reports whether c applies to a deposed object rather than the
+// current object of its resource instance.
+func (c Change) IsDeposed() bool {
+	return c.Deposed != ""
+}
+
+// ObjectAddress is the address of the object c applies to: Address, followed
+// by the deposed key for deposed objects, e.g.
+// "aws_instance.web (deposed object 1a2b3c4d)". It tells a deposed object
+// apart from the current object of the same resource instance.
+func (c Change) ObjectAddress() string {
+	if c.IsDeposed() {
+		return c.Address + " (deposed object " + c.Deposed + ")"
+	}
+	return c.Address
+}
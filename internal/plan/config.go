@@ -0,0 +1,120 @@
+package plan
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type tfConfiguration struct {
+	RootModule configModule `json:"root_module"`
+}
+
+type configModule struct {
+	ModuleCalls map[string]moduleCall `json:"module_calls"`
+}
+
+type moduleCall struct {
+	Source string       `json:"source"`
+	Module configModule `json:"module"`
+}
+
+// Location is the place in the configuration where a resource is declared.
+type Location struct {
+	File string // slash-separated, relative to the root module directory
+	Line int
+}
+
+var instanceKeyRe = regexp.MustCompile(`\[[^\]]*\]`)
+
+// moduleDirs maps module configuration addresses ("" for the root module,
+// "module.db", "module.db.module.subnets", ...) to their directories relative
+// to the root module. Only local module sources ("./", "../") can be resolved.
+func moduleDirs(cfg tfConfiguration) map[string]string {
+	dirs := map[string]string{"": "."}
+	var walk func(prefix, dir string, m configModule)
+	walk = func(prefix, dir string, m configModule) {
+		for name, call := range m.ModuleCalls {
+			if !strings.HasPrefix(call.Source, "./") && !strings.HasPrefix(call.Source, "../") {
+				continue
+			}
+			addr := "module." + name
+			if prefix != "" {
+				addr = prefix + "." + addr
+			}
+			childDir := filepath.Join(dir, call.Source)
+			dirs[addr] = childDir
+			walk(addr, childDir, call.Module)
+		}
+	}
+	walk("", ".", cfg.RootModule)
+	return dirs
+}
+
+// Locate finds the file and line declaring c by scanning the .tf files of its
+// module directory below root. It reports false when the module source is not
+// local or no matching resource/data block is found.
+func Locate(root string, s Stats, c Change) (Location, bool) {
+	modAddr := instanceKeyRe.ReplaceAllString(c.ModuleAddress, "")
+	dir, ok := s.ModuleDirs[modAddr]
+	if !ok {
+		if modAddr != "" {
+			return Location{}, false
+		}
+		dir = "."
+	}
+
+	block := "resource"
+	if c.Mode == ModeData {
+		block = "data"
+	}
+	header := []string{block, `"` + c.Type + `"`, `"` + c.Name + `"`}
+
+	files, _ := filepath.Glob(filepath.Join(root, dir, "*.tf"))
+	for _, f := range files {
+		if line := findLine(f, header); line > 0 {
+			rel, err := filepath.Rel(root, f)
+			if err != nil {
+				rel = f
+			}
+			return Location{File: filepath.ToSlash(rel), Line: line}, true
+		}
+	}
+	return Location{}, false
+}
+
+// findLine returns the first line of path whose leading tokens are header,
+// e.g. `resource "aws_instance" "web" {`, or 0 when there is none.
+func findLine(path string, header []string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		if isBlockHeader(sc.Text(), header) {
+			return n
+		}
+	}
+	return 0
+}
+
+// isBlockHeader reports whether line starts with the tokens of header. The
+// last token may be followed directly by the opening brace.
+func isBlockHeader(line string, header []string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < len(header) {
+		return false
+	}
+	last := len(header) - 1
+	for i, tok := range header[:last] {
+		if fields[i] != tok {
+			return false
+		}
+	}
+	return fields[last] == header[last] || strings.HasPrefix(fields[last], header[last]+"{")
+}
@@ -0,0 +1,60 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "main.tf"), "terraform {}\n\nresource \"aws_instance\" \"web\" {\n  ami = \"x\"\n}\n")
+	writeFile(t, filepath.Join(root, "s3.tf"), "resource \"aws_s3_bucket\" \"logs_old\" {}\nresource  \"aws_s3_bucket\"  \"logs\"{}\n")
+	writeFile(t, filepath.Join(root, "modules", "db", "db.tf"), "data \"aws_caller_identity\" \"me\" {}\n\nresource \"aws_db_instance\" \"main\" {}\n")
+
+	planJSON := []byte(`{
+  "resource_changes": [],
+  "configuration": {
+    "root_module": {
+      "module_calls": {
+        "db": { "source": "./modules/db", "module": {} },
+        "vpc": { "source": "terraform-aws-modules/vpc/aws", "module": {} }
+      }
+    }
+  }
+}`)
+	s, err := ParseStats(planJSON)
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+
+	cases := []struct {
+		change Change
+		file   string
+		line   int
+		ok     bool
+	}{
+		{Change{Type: "aws_instance", Name: "web", Mode: "managed"}, "main.tf", 3, true},
+		{Change{Type: "aws_s3_bucket", Name: "logs", Mode: "managed"}, "s3.tf", 2, true},
+		{Change{ModuleAddress: "module.db[0]", Type: "aws_db_instance", Name: "main", Mode: "managed"}, "modules/db/db.tf", 3, true},
+		{Change{ModuleAddress: "module.db", Type: "aws_caller_identity", Name: "me", Mode: ModeData}, "modules/db/db.tf", 1, true},
+		{Change{ModuleAddress: "module.vpc", Type: "aws_vpc", Name: "this", Mode: "managed"}, "", 0, false},
+		{Change{Type: "aws_instance", Name: "missing", Mode: "managed"}, "", 0, false},
+	}
+	for _, c := range cases {
+		loc, ok := Locate(root, s, c.change)
+		if ok != c.ok || loc.File != c.file || loc.Line != c.line {
+			t.Fatalf("Locate(%s.%s) = %+v, %v; want %s:%d, %v", c.change.Type, c.change.Name, loc, ok, c.file, c.line, c.ok)
+		}
+	}
+}
+
+func writeFile(t *testing.T, p, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,143 @@
+package plan
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Diagnostic is an error or warning reported by the runner in its
+// machine-readable UI output (`plan -json`).
+type Diagnostic struct {
+	Severity string           `json:"severity"` // "error" or "warning"
+	Summary  string           `json:"summary"`
+	Detail   string           `json:"detail,omitempty"`
+	Address  string           `json:"address,omitempty"`
+	Range    *DiagnosticRange `json:"range,omitempty"`
+}
+
+// DiagnosticRange is the source range a diagnostic refers to.
+type DiagnosticRange struct {
+	Filename string        `json:"filename"`
+	Start    DiagnosticPos `json:"start"`
+	End      DiagnosticPos `json:"end"`
+}
+
+type DiagnosticPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Location returns "file:line" for diagnostics with a source range, else "".
+func (d Diagnostic) Location() string {
+	if d.Range == nil || d.Range.Filename == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", d.Range.Filename, d.Range.Start.Line)
+}
+
+// String renders the diagnostic on one line, e.g.
+// "Error: Invalid provider configuration (providers.tf:3)".
+func (d Diagnostic) String() string {
+	sev := "Error"
+	if d.Severity == "warning" {
+		sev = "Warning"
+	}
+	s := sev + ": " + d.Summary
+	if loc := d.Location(); loc != "" {
+		s += " (" + loc + ")"
+	}
+	return s
+}
+
+// uiMessage is one line of the runner's machine-readable UI output. Only the
+// fields drift-checker uses are decoded.
+type uiMessage struct {
+	Level      string      `json:"@level"`
+	Message    string      `json:"@message"`
+	Timestamp  string      `json:"@timestamp"`
+	Type       string      `json:"type"`
+	Diagnostic *Diagnostic `json:"diagnostic,omitempty"`
+	Hook       *uiHook     `json:"hook,omitempty"`
+}
+
+// uiHook is the payload of resource hook messages such as refresh_start.
+type uiHook struct {
+	Resource struct {
+		Addr string `json:"addr"`
+	} `json:"resource"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+}
+
+// progressEvent converts m into a ProgressEvent; now is used when the message
+// has no valid timestamp.
+func (m uiMessage) progressEvent(now time.Time) ProgressEvent {
+	e := ProgressEvent{Type: m.Type, Time: now, Message: m.Message}
+	if t, err := time.Parse(time.RFC3339Nano, m.Timestamp); err == nil {
+		e.Time = t
+	}
+	if m.Hook != nil {
+		e.Address = m.Hook.Resource.Addr
+		e.Elapsed = time.Duration(m.Hook.ElapsedSeconds * float64(time.Second))
+	}
+	return e
+}
+
+// decodeUIStream calls fn for every JSON message read from r as it arrives.
+// Lines that are not JSON (e.g. Terragrunt's own output) are skipped. r is
+// always read to EOF, so a writer feeding it never blocks.
+func decodeUIStream(r io.Reader, fn func(uiMessage)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var m uiMessage
+		if err := json.Unmarshal(line, &m); err != nil {
+			continue
+		}
+		fn(m)
+	}
+	if err := sc.Err(); err != nil {
+		_, _ = io.Copy(io.Discard, r)
+		return err
+	}
+	return nil
+}
+
+// Diagnostics returns the diagnostics of the RunnerError in err's chain, if any.
+func Diagnostics(err error) []Diagnostic {
+	var re *RunnerError
+	if errors.As(err, &re) {
+		return re.Diagnostics
+	}
+	return nil
+}
+
+// ErrorDiagnostics returns the diagnostics with error severity.
+func ErrorDiagnostics(diags []Diagnostic) []Diagnostic {
+	var out []Diagnostic
+	for _, d := range diags {
+		if d.Severity != "warning" {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func diagnosticsText(diags []Diagnostic) string {
+	var b strings.Builder
+	for _, d := range diags {
+		b.WriteString(d.Summary)
+		b.WriteString("\n")
+		b.WriteString(d.Detail)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
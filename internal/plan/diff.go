@@ -0,0 +1,195 @@
+package plan
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// AttributeChange describes a single attribute path whose value differs between
+// the before and after objects of a resource change.
+type AttributeChange struct {
+	Path      string
+	Kind      DiffKind
+	Before    any
+	After     any
+	Unknown   bool // after value is only known after apply
+	Sensitive bool // value is marked sensitive in the plan
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// diffObjects computes the attribute-level differences of a resource change.
+// Create and delete changes (where one side is null) have no attribute diff.
+func diffObjects(cd changeDetail) []AttributeChange {
+	if cd.Before == nil || cd.After == nil {
+		return nil
+	}
+	var out []AttributeChange
+	diffValue("", cd.Before, cd.After, cd.AfterUnknown, cd.BeforeSensitive, cd.AfterSensitive, &out)
+	return out
+}
+
+// diffValue appends the differences between before and after at path. Maps
+// and lists are compared element by element, also when one side is null, so
+// that nested sensitive markers apply to their own values. A change recorded
+// as a whole (a scalar, a type change, an unknown or fully sensitive subtree)
+// is sensitive when any marker within it is.
+func diffValue(path string, before, after, unknown, beforeSens, afterSens any, out *[]AttributeChange) {
+	sensitive := hasTrue(beforeSens) || hasTrue(afterSens)
+
+	if isTrue(unknown) {
+		kind := DiffChanged
+		if before == nil {
+			kind = DiffAdded
+		}
+		*out = append(*out, AttributeChange{Path: path, Kind: kind, Before: before, Unknown: true, Sensitive: sensitive})
+		return
+	}
+
+	if !isTrue(beforeSens) && !isTrue(afterSens) {
+		bm, bok := before.(map[string]any)
+		am, aok := after.(map[string]any)
+		switch {
+		case bok && after == nil && len(bm) > 0:
+			am, aok = map[string]any{}, true
+		case aok && before == nil && len(am) > 0:
+			bm, bok = map[string]any{}, true
+		}
+		if bok && aok {
+			um, _ := unknown.(map[string]any)
+			for _, k := range unionKeys(bm, am, um) {
+				diffValue(joinKey(path, k), bm[k], am[k],
+					childByKey(unknown, k), childByKey(beforeSens, k), childByKey(afterSens, k), out)
+			}
+			return
+		}
+
+		bl, bok := before.([]any)
+		al, aok := after.([]any)
+		switch {
+		case bok && after == nil && len(bl) > 0:
+			al, aok = []any{}, true
+		case aok && before == nil && len(al) > 0:
+			bl, bok = []any{}, true
+		}
+		if bok && aok {
+			n := max(len(bl), len(al))
+			for i := range n {
+				diffValue(joinIndex(path, i), indexOrNil(bl, i), indexOrNil(al, i),
+					childByIndex(unknown, i), childByIndex(beforeSens, i), childByIndex(afterSens, i), out)
+			}
+			return
+		}
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	kind := DiffChanged
+	switch {
+	case before == nil:
+		kind = DiffAdded
+	case after == nil:
+		kind = DiffRemoved
+	}
+	*out = append(*out, AttributeChange{Path: path, Kind: kind, Before: before, After: after, Sensitive: sensitive})
+}
+
+func isTrue(v any) bool {
+	b, ok := v.(bool)
+	return ok && b
+}
+
+// hasTrue reports whether a marker structure is true anywhere within it.
+func hasTrue(marker any) bool {
+	switch m := marker.(type) {
+	case bool:
+		return m
+	case map[string]any:
+		for _, v := range m {
+			if hasTrue(v) {
+				return true
+			}
+		}
+	case []any:
+		for _, v := range m {
+			if hasTrue(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// childByKey walks one level into a marker structure (after_unknown, *_sensitive).
+// A `true` marker applies to the whole subtree.
+func childByKey(marker any, key string) any {
+	switch m := marker.(type) {
+	case bool:
+		if m {
+			return true
+		}
+	case map[string]any:
+		return m[key]
+	}
+	return nil
+}
+
+func childByIndex(marker any, i int) any {
+	switch m := marker.(type) {
+	case bool:
+		if m {
+			return true
+		}
+	case []any:
+		return indexOrNil(m, i)
+	}
+	return nil
+}
+
+func indexOrNil(l []any, i int) any {
+	if i < len(l) {
+		return l[i]
+	}
+	return nil
+}
+
+func unionKeys(maps ...map[string]any) []string {
+	seen := map[string]struct{}{}
+	for _, m := range maps {
+		for k := range m {
+			seen[k] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinKey appends a map key to an attribute path, e.g. tags.Name or tags["kubernetes.io/role"].
+func joinKey(path, key string) string {
+	if !identRe.MatchString(key) {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func joinIndex(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
@@ -0,0 +1,179 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCategory classifies why a runner command failed.
+type ErrorCategory string
+
+const (
+	CategoryUnknown        ErrorCategory = ""
+	CategoryNotInitialized ErrorCategory = "not-initialized"
+	CategoryLocked         ErrorCategory = "state-locked" // state lock held by another operation
+	CategoryThrottled      ErrorCategory = "throttled"    // provider API rate limiting
+	CategoryNetwork        ErrorCategory = "network"      // timeouts, resets, DNS failures
+	CategoryAuth           ErrorCategory = "auth"         // missing, invalid or expired credentials
+	CategoryConfig         ErrorCategory = "config"       // invalid configuration or variables
+)
+
+// Retryable reports whether failures of category c are usually transient.
+func (c ErrorCategory) Retryable() bool {
+	switch c {
+	case CategoryLocked, CategoryThrottled, CategoryNetwork:
+		return true
+	}
+	return false
+}
+
+// ErrNotInitialized matches (with errors.Is) runner failures caused by a
+// working directory that needs `init` first.
+var ErrNotInitialized = errors.New("working directory is not initialized")
+
+// RunnerError is a failed runner command together with its classified cause.
+type RunnerError struct {
+	Runner   RunnerKind
+	Op       string // e.g. "plan", "show -json", "init"
+	Category ErrorCategory
+	Stderr   string
+	// Diagnostics are the runner's diagnostics from its -json UI output
+	// (only for commands run with -json).
+	Diagnostics []Diagnostic
+	Err         error // the exec error (e.g. exit status)
+}
+
+func (e *RunnerError) Error() string {
+	msg := fmt.Sprintf("%s %s failed: %v", e.Runner, e.Op, e.Err)
+	if e.Category == CategoryNotInitialized {
+		msg = fmt.Sprintf("%s %s failed: %v (run with --init, or '%s init' first)", e.Runner, e.Op, ErrNotInitialized, e.Runner)
+	}
+	if diags := ErrorDiagnostics(e.Diagnostics); len(diags) > 0 {
+		for _, d := range diags {
+			msg += "\n" + d.String()
+		}
+	} else if e.Stderr != "" {
+		msg += "\n" + e.Stderr
+	}
+	return msg
+}
+
+func (e *RunnerError) Unwrap() error { return e.Err }
+
+func (e *RunnerError) Is(target error) bool {
+	return target == ErrNotInitialized && e.Category == CategoryNotInitialized
+}
+
+// Category returns the category of the RunnerError in err's chain, if any.
+func Category(err error) ErrorCategory {
+	var re *RunnerError
+	if errors.As(err, &re) {
+		return re.Category
+	}
+	return CategoryUnknown
+}
+
+// categoryMarkers are lowercase message fragments printed by Terraform,
+// OpenTofu and common providers, per failure category. The first matching
+// category wins.
+var categoryMarkers = []struct {
+	category ErrorCategory
+	markers  []string
+}{
+	{CategoryNotInitialized, []string{
+		"please run \"terraform init\"",
+		"please run \"tofu init\"",
+		"run \"terraform init\"",
+		"run \"tofu init\"",
+		"backend initialization required",
+		"module not installed",
+		"required plugins are not installed",
+		"missing required provider",
+		"inconsistent dependency lock file",
+		"could not load plugin",
+	}},
+	{CategoryLocked, []string{
+		"error acquiring the state lock",
+		"error locking state",
+		"state blob is already locked",
+		"conditionalcheckfailedexception",
+	}},
+	{CategoryAuth, []string{
+		"no valid credential sources",
+		"invalidclienttokenid",
+		"expiredtoken",
+		"security token included in the request is expired",
+		"security token included in the request is invalid",
+		"could not find default credentials",
+		"failed to refresh cached credentials",
+		"authentication failed",
+		"authorizationfailed",
+		"unauthorized",
+		"accessdenied",
+		"access denied",
+		"permission denied",
+	}},
+	{CategoryThrottled, []string{
+		"throttling",
+		"throttled",
+		"rate exceeded",
+		"ratelimitexceeded",
+		"requestlimitexceeded",
+		"rate limit",
+		"too many requests",
+		"toomanyrequests",
+		"slowdown",
+	}},
+	{CategoryNetwork, []string{
+		"i/o timeout",
+		"connection reset",
+		"connection refused",
+		"tls handshake timeout",
+		"no such host",
+		"temporary failure in name resolution",
+		"timeout awaiting response headers",
+		"client.timeout exceeded",
+		"unexpected eof",
+		"broken pipe",
+	}},
+	{CategoryConfig, []string{
+		"invalid reference",
+		"reference to undeclared",
+		"unsupported argument",
+		"unsupported block type",
+		"missing required argument",
+		"argument or block definition required",
+		"no value for required variable",
+		"invalid value for variable",
+		"invalid expression",
+		"unsuitable value type",
+		"duplicate resource",
+		"failed to read variables file",
+	}},
+}
+
+func newRunnerError(runner RunnerKind, op string, err error, stderr string, diags ...Diagnostic) *RunnerError {
+	return &RunnerError{
+		Runner:      runner,
+		Op:          op,
+		Category:    classify(stderr + diagnosticsText(diags)),
+		Stderr:      stderr,
+		Diagnostics: diags,
+		Err:         err,
+	}
+}
+
+// classify derives the failure category from the runner's stderr and
+// diagnostic messages.
+func classify(stderr string) ErrorCategory {
+	s := strings.ToLower(stderr)
+	for _, c := range categoryMarkers {
+		for _, m := range c.markers {
+			if strings.Contains(s, m) {
+				return c.category
+			}
+		}
+	}
+	return CategoryUnknown
+}
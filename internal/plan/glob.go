@@ -0,0 +1,59 @@
+package plan
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// globs caches the compiled form of every pattern MatchGlob has seen, since
+// the same exemption and policy patterns are matched against every change.
+var globs = struct {
+	sync.Mutex
+	re map[string]*regexp.Regexp
+}{re: map[string]*regexp.Regexp{}}
+
+// MatchGlob reports whether s matches pattern, where '*' matches any sequence
+// of characters (including '.') and '?' matches a single character. All other
+// characters, including '[' and ']', match literally so resource addresses
+// such as aws_instance.web["a"] can be used as patterns.
+func MatchGlob(pattern, s string) bool {
+	globs.Lock()
+	re, ok := globs.re[pattern]
+	if !ok {
+		re = compileGlob(pattern)
+		globs.re[pattern] = re
+	}
+	globs.Unlock()
+	return re.MatchString(s)
+}
+
+// compileGlob translates pattern into an anchored regular expression. Every
+// character other than '*' and '?' is quoted, so compilation cannot fail.
+func compileGlob(pattern string) *regexp.Regexp {
+	var re strings.Builder
+	re.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String())
+}
+
+// MatchModule reports whether a module address matches pattern. A pattern also
+// covers the module's nested modules, so "module.network" matches
+// "module.network.module.subnets", and a pattern without instance keys covers
+// every instance of a count or for_each module, e.g. `module.network["a"]`.
+func MatchModule(pattern, moduleAddress string) bool {
+	match := func(addr string) bool {
+		return MatchGlob(pattern, addr) || MatchGlob(pattern+".*", addr)
+	}
+	return match(moduleAddress) || match(instanceKeyRe.ReplaceAllString(moduleAddress, ""))
+}
@@ -0,0 +1,38 @@
+package plan
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"aws_instance.*", "aws_instance.web", true},
+		{"module.network.*", "module.network.aws_vpc.main", true},
+		{`aws_instance.web["a"]`, `aws_instance.web["a"]`, true},
+		{"aws_instance.web[?]", "aws_instance.web[0]", true},
+		{"aws_instance.*", "aws_s3_bucket.logs", false},
+	}
+	for _, c := range cases {
+		if got := MatchGlob(c.pattern, c.s); got != c.want {
+			t.Fatalf("MatchGlob(%q, %q) = %v, want %v", c.pattern, c.s, got, c.want)
+		}
+	}
+}
+
+func TestMatchModule(t *testing.T) {
+	if !MatchModule("module.network", "module.network.module.subnets") {
+		t.Fatalf("expected nested module to match")
+	}
+	if MatchModule("module.network", "module.networking") {
+		t.Fatalf("expected sibling module with common prefix not to match")
+	}
+	for _, addr := range []string{"module.network[0]", `module.network["a"]`, `module.network["a"].module.subnets[1]`} {
+		if !MatchModule("module.network", addr) {
+			t.Fatalf("expected module instance %s to match", addr)
+		}
+	}
+	if !MatchModule(`module.network["a"]`, `module.network["a"].module.subnets`) || MatchModule(`module.network["a"]`, `module.network["b"]`) {
+		t.Fatalf("expected a pattern with an instance key to match that instance only")
+	}
+}
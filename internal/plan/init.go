@@ -0,0 +1,65 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// initialized tracks the directories Init already ran in, keyed by runner,
+// absolute path and backend configuration.
+var initialized = struct {
+	sync.Mutex
+	dirs map[string]*initState
+}{dirs: map[string]*initState{}}
+
+type initState struct {
+	sync.Mutex
+	done bool
+}
+
+// Init runs `<runner> init -input=false -upgrade=false [-backend-config=...]`
+// in the working directory path. Each directory is initialized at most once
+// per process and never concurrently, so parallel scans of several workspaces
+// of one directory share a single init. A failed init is retried on the next call.
+func Init(ctx context.Context, runner RunnerKind, path string, opts PlanOptions) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	key := strings.Join(append([]string{string(runner), abs}, opts.BackendConfig...), "\x00")
+
+	initialized.Lock()
+	st, ok := initialized.dirs[key]
+	if !ok {
+		st = &initState{}
+		initialized.dirs[key] = st
+	}
+	initialized.Unlock()
+
+	st.Lock()
+	defer st.Unlock()
+	if st.done {
+		return nil
+	}
+
+	args := []string{"init", "-input=false", "-upgrade=false"}
+	for _, bc := range opts.BackendConfig {
+		args = append(args, "-backend-config="+bc)
+	}
+	// init does not depend on the selected workspace, and TF_WORKSPACE naming
+	// a workspace that does not exist yet would make it fail.
+	opts.Workspace = ""
+	cmd := command(ctx, runner, path, args...)
+	cmd.Env = opts.env(cmd)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := run(cmd); err != nil {
+		return newRunnerError(runner, "init", err, stderr.String())
+	}
+	st.done = true
+	return nil
+}
@@ -0,0 +1,47 @@
+package plan
+
+import "sort"
+
+// OutputChange is a root module output whose value would change. Outputs
+// often change when the infrastructure behind them drifts (an endpoint, an
+// IP address), which affects whatever consumes them: other stacks, DNS.
+type OutputChange struct {
+	Name    string
+	Actions []string
+	// Attributes is the value diff. Paths start with the output name, e.g.
+	// endpoint or endpoints[0].host.
+	Attributes []AttributeChange
+}
+
+// Action returns the normalized action of the output change.
+func (o OutputChange) Action() Action {
+	return ClassifyActions(o.Actions)
+}
+
+// toOutputChanges converts output_changes into the outputs that change, sorted
+// by name. Sensitive values are marked (see AttributeChange.Sensitive).
+func toOutputChanges(raw map[string]changeDetail) []OutputChange {
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []OutputChange
+	for _, name := range names {
+		cd := raw[name]
+		if ClassifyActions(cd.Actions) == ActionNoOp {
+			continue
+		}
+		// Diff the values below the output name, so that creating or removing
+		// an output (one side null) still yields an entry.
+		var attrs []AttributeChange
+		diffValue("", map[string]any{name: cd.Before}, map[string]any{name: cd.After},
+			map[string]any{name: cd.AfterUnknown}, map[string]any{name: cd.BeforeSensitive}, map[string]any{name: cd.AfterSensitive}, &attrs)
+		if len(attrs) == 0 {
+			continue
+		}
+		out = append(out, OutputChange{Name: name, Actions: cd.Actions, Attributes: attrs})
+	}
+	return out
+}
@@ -1,33 +1,138 @@
 package plan
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"slices"
 )
 
 type tfPlan struct {
-	ResourceChanges []resourceChange `json:"resource_changes"`
+	FormatVersion    string                  `json:"format_version"`
+	TerraformVersion string                  `json:"terraform_version"`
+	ResourceChanges  []resourceChange        `json:"resource_changes"`
+	ResourceDrift    []resourceChange        `json:"resource_drift"`
+	OutputChanges    map[string]changeDetail `json:"output_changes"`
+	Configuration    tfConfiguration         `json:"configuration"`
 }
 
 type resourceChange struct {
-	Address string       `json:"address"`
-	Mode    string       `json:"mode"`
-	Type    string       `json:"type"`
-	Name    string       `json:"name"`
-	Change  changeDetail `json:"change"`
+	Address         string       `json:"address"`
+	PreviousAddress string       `json:"previous_address"`
+	ModuleAddress   string       `json:"module_address"`
+	Mode            string       `json:"mode"`
+	Type            string       `json:"type"`
+	Name            string       `json:"name"`
+	ProviderName    string       `json:"provider_name"`
+	ActionReason    string       `json:"action_reason"`
+	Deposed         string       `json:"deposed"`
+	Change          changeDetail `json:"change"`
 }
 
 type changeDetail struct {
-	Actions []string `json:"actions"`
+	Actions         []string `json:"actions"`
+	Before          any      `json:"before"`
+	After           any      `json:"after"`
+	AfterUnknown    any      `json:"after_unknown"`
+	BeforeSensitive any      `json:"before_sensitive"`
+	AfterSensitive  any      `json:"after_sensitive"`
+	Importing       *Import  `json:"importing"`
+	ReplacePaths    [][]any  `json:"replace_paths"`
+}
+
+// Import is the "importing" object of a change that imports an existing
+// object into state.
+type Import struct {
+	ID string `json:"id,omitempty"` // empty when importing by identity
+}
+
+// Change is a single resource change from the plan together with its attribute diff.
+type Change struct {
+	Address         string
+	PreviousAddress string  // address before a move; empty when not moved
+	Importing       *Import // set when the change imports an existing object
+	ModuleAddress   string  // empty for resources in the root module
+	Mode            string
+	Type            string
+	Name            string
+	ProviderName    string
+	Actions         []string
+	ActionReason    string   // the plan's action_reason (see the Reason constants), if any
+	ReplacePaths    []string // attributes forcing a replacement (replace_paths)
+	Deposed         string   // deposed object key; empty for the current object
+	Attributes      []AttributeChange
 }
 
 type Stats struct {
-	Updates          int
-	Deletes          int
-	Replaces         int
+	Updates  int
+	Deletes  int
+	Replaces int
+	// CreateBeforeDestroy and DeleteBeforeCreate split Replaces by the order
+	// of its actions: ["create","delete"] (create_before_destroy) replaces keep
+	// the old object until the new one exists, ["delete","create"] ones do not.
+	CreateBeforeDestroy int
+	DeleteBeforeCreate  int
+	// Deposed lists the deposed objects in resource_changes: objects left
+	// behind by a create-before-destroy replace whose apply failed to destroy
+	// them. They are also counted by their action (normally a delete).
+	Deposed []Change
+	// Moves, Imports and Forgets count refactoring changes in resource_changes:
+	// resources with a previous_address, resources being imported, and
+	// resources removed from state without being destroyed. A moved or imported
+	// resource may also be counted as an update (or replace) when its
+	// attributes change too; on their own they are not drift.
+	Moves            int
+	Imports          int
+	Forgets          int
 	DriftedResources []string
+	// TotalResources counts every entry of resource_changes: ManagedResources
+	// plus DataSources.
 	TotalResources   int
+	ManagedResources int
+	DataSources      int
+	// DeferredReads lists the data sources read during apply rather than
+	// during the plan (["read"] actions), because their configuration or a
+	// dependency is not known yet (see the Read reason constants).
+	DeferredReads []Change
+	// DataChanges lists the data sources, in resource_changes or
+	// resource_drift, whose read result differs from the prior state. Data
+	// sources are never drift themselves, but a changed result often explains
+	// drift downstream.
+	DataChanges []Change
+	// Outputs lists the root module outputs whose value would change
+	// (output_changes), sorted by name.
+	Outputs    []OutputChange
+	Changes    []Change // drifted resources only, in the same order as DriftedResources
+	External   ChangeSummary
+	Suppressed []Suppressed // drift hidden by exemptions (see Filter)
+	Resources  []Change     // every entry of resource_changes, including creates, reads and no-ops
+	// ModuleDirs maps module configuration addresses to their local source
+	// directories, relative to the root module (see Locate).
+	ModuleDirs map[string]string
+	// FormatVersion and TerraformVersion are the plan's format_version and
+	// terraform_version (the version of the binary that produced it).
+	FormatVersion    string
+	TerraformVersion string
+	Warnings         []string // compatibility warnings found while parsing
+}
+
+// ChangeSummary counts the updates, deletes and replaces of managed resources
+// in a list of changes. Parse builds one for the planned changes of
+// resource_changes (copied into the Stats counters) and one for resource_drift,
+// the changes made outside of IaC that were detected while refreshing state.
+type ChangeSummary struct {
+	Updates             int
+	Deletes             int
+	Replaces            int
+	CreateBeforeDestroy int
+	DeleteBeforeCreate  int
+	Resources           []string
+	Changes             []Change
+}
+
+// Count returns the number of summarized resources.
+func (d ChangeSummary) Count() int {
+	return d.Updates + d.Replaces + d.Deletes
 }
 
 // DriftCount returns the total number of drifted changes as the sum of Updates, Replaces, and Deletes.
@@ -35,43 +140,166 @@ func DriftCount(s Stats) int {
 	return s.Updates + s.Replaces + s.Deletes
 }
 
+// DriftedAddresses returns the addresses of planned and external drift, each
+// once: a resource changed outside of IaC that the plan reverts is listed in
+// both DriftedResources and External.Resources.
+func DriftedAddresses(s Stats) []string {
+	out := slices.Clone(s.DriftedResources)
+	for _, addr := range s.External.Resources {
+		if !slices.Contains(s.DriftedResources, addr) {
+			out = append(out, addr)
+		}
+	}
+	return out
+}
+
+// ChangeFor returns the planned change recorded for the given address, if any.
+func (s Stats) ChangeFor(address string) (Change, bool) {
+	return FindChange(s.Changes, address)
+}
+
+// FindChange returns the change with the given object address (see
+// Change.ObjectAddress), if any.
+func FindChange(changes []Change, address string) (Change, bool) {
+	for _, c := range changes {
+		if c.ObjectAddress() == address {
+			return c, true
+		}
+	}
+	return Change{}, false
+}
+
 // ParseStats extracts counts from Terraform/OpenTofu plan JSON (from `show -json`).
 func ParseStats(planJSON []byte) (Stats, error) {
 	var p tfPlan
-	if err := json.Unmarshal(planJSON, &p); err != nil {
+	// UseNumber keeps attribute values such as large IDs intact in diffs.
+	dec := json.NewDecoder(bytes.NewReader(planJSON))
+	dec.UseNumber()
+	if err := dec.Decode(&p); err != nil {
 		return Stats{}, fmt.Errorf("invalid plan JSON: %w", err)
 	}
-
-	stats := Stats{
-		TotalResources: len(p.ResourceChanges),
+	warnings, err := checkFormat(p.FormatVersion, p.TerraformVersion)
+	if err != nil {
+		return Stats{}, err
 	}
 
-	for _, rc := range p.ResourceChanges {
-		acts := rc.Change.Actions
-		if len(acts) == 0 {
-			continue
+	resources := toChanges(p.ResourceChanges)
+	planned := summarize(resources)
+	drift := toChanges(p.ResourceDrift)
+	external := summarize(drift)
+
+	var moves, imports, forgets, data int
+	var deposed, reads, dataChanges []Change
+	for _, c := range resources {
+		if c.IsData() {
+			data++
+			switch {
+			case c.Action() == ActionRead:
+				reads = append(reads, c)
+			case len(c.Attributes) > 0:
+				dataChanges = append(dataChanges, c)
+			}
+		}
+		if c.IsDeposed() {
+			deposed = append(deposed, c)
+		}
+		if c.Moved() {
+			moves++
+		}
+		if c.Imported() {
+			imports++
 		}
-		// Recognize actions:
-		// - ["update"]
-		// - ["delete"]
-		// - ["create","delete"] or ["delete","create"] => replace
-		// (refresh-only plans surface drift via update/delete/replace)
-		isReplace := (slices.Contains(acts, "create") && slices.Contains(acts, "delete"))
-		isUpdate := len(acts) == 1 && acts[0] == "update"
-		isDelete := len(acts) == 1 && acts[0] == "delete"
-
-		switch {
-		case isReplace:
-			stats.Replaces++
-			stats.DriftedResources = append(stats.DriftedResources, rc.Address)
-		case isUpdate:
-			stats.Updates++
-			stats.DriftedResources = append(stats.DriftedResources, rc.Address)
-		case isDelete:
-			stats.Deletes++
-			stats.DriftedResources = append(stats.DriftedResources, rc.Address)
+		if c.Action() == ActionForget {
+			forgets++
 		}
 	}
+	for _, c := range drift {
+		if c.IsData() && len(c.Attributes) > 0 {
+			dataChanges = append(dataChanges, c)
+		}
+	}
+
+	return Stats{
+		Updates:             planned.Updates,
+		Deletes:             planned.Deletes,
+		Replaces:            planned.Replaces,
+		CreateBeforeDestroy: planned.CreateBeforeDestroy,
+		DeleteBeforeCreate:  planned.DeleteBeforeCreate,
+		Deposed:             deposed,
+		Moves:               moves,
+		Imports:             imports,
+		Forgets:             forgets,
+		DriftedResources:    planned.Resources,
+		TotalResources:      len(p.ResourceChanges),
+		ManagedResources:    len(p.ResourceChanges) - data,
+		DataSources:         data,
+		DeferredReads:       reads,
+		DataChanges:         dataChanges,
+		Outputs:             toOutputChanges(p.OutputChanges),
+		Changes:             planned.Changes,
+		External:            external,
+		Resources:           resources,
+		ModuleDirs:          moduleDirs(p.Configuration),
+		FormatVersion:       p.FormatVersion,
+		TerraformVersion:    p.TerraformVersion,
+		Warnings:            warnings,
+	}, nil
+}
+
+func toChanges(rcs []resourceChange) []Change {
+	out := make([]Change, 0, len(rcs))
+	for _, rc := range rcs {
+		out = append(out, Change{
+			Address:         rc.Address,
+			PreviousAddress: rc.PreviousAddress,
+			Importing:       rc.Change.Importing,
+			ModuleAddress:   rc.ModuleAddress,
+			Mode:            rc.Mode,
+			Type:            rc.Type,
+			Name:            rc.Name,
+			ProviderName:    rc.ProviderName,
+			Actions:         rc.Change.Actions,
+			ActionReason:    rc.ActionReason,
+			ReplacePaths:    replacePaths(rc.Change.ReplacePaths),
+			Deposed:         rc.Deposed,
+			Attributes:      diffObjects(rc.Change),
+		})
+	}
+	return out
+}
 
-	return stats, nil
+// summarize classifies a list of changes (from either resource_changes or
+// resource_drift) into update/delete/replace counts.
+func summarize(changes []Change) ChangeSummary {
+	var out ChangeSummary
+	for _, c := range changes {
+		out.add(c)
+	}
+	return out
+}
+
+// add records c when it is an update, delete or replace of a managed resource
+// (refresh-only plans surface drift via update/delete/replace). Data sources
+// are not drift; see Stats.DataChanges.
+func (d *ChangeSummary) add(c Change) {
+	if c.IsData() {
+		return
+	}
+	switch c.Action() {
+	case ActionReplace:
+		d.Replaces++
+		if c.CreateBeforeDestroy() {
+			d.CreateBeforeDestroy++
+		} else {
+			d.DeleteBeforeCreate++
+		}
+	case ActionUpdate:
+		d.Updates++
+	case ActionDelete:
+		d.Deletes++
+	default:
+		return
+	}
+	d.Resources = append(d.Resources, c.ObjectAddress())
+	d.Changes = append(d.Changes, c)
 }
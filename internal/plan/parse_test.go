@@ -3,6 +3,7 @@ package plan
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -63,4 +64,251 @@ func TestParseStats_Drift(t *testing.T) {
 	if s.TotalResources != 3 {
 		t.Fatalf("expected TotalResources 3, got %d", s.TotalResources)
 	}
-}
\ No newline at end of file
+}
+
+func TestParseStats_AttributeDiff(t *testing.T) {
+	b := mustRead(t, filepath.Join("testdata", "plan_attributes.json"))
+
+	s, err := ParseStats(b)
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+
+	web, ok := s.ChangeFor("aws_instance.web")
+	if !ok {
+		t.Fatalf("expected change for aws_instance.web, got %+v", s.Changes)
+	}
+	got := map[string]AttributeChange{}
+	for _, a := range web.Attributes {
+		got[a.Path] = a
+	}
+	want := map[string]DiffKind{
+		"instance_type":              DiffChanged,
+		"tags.Owner":                 DiffRemoved,
+		`tags["kubernetes.io/role"]`: DiffAdded,
+		"security_groups[1]":         DiffRemoved,
+		"user_data":                  DiffChanged,
+		"root_block_device[0].iops":  DiffAdded,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d attribute changes, got %d: %+v", len(want), len(got), web.Attributes)
+	}
+	for path, kind := range want {
+		a, ok := got[path]
+		if !ok {
+			t.Fatalf("missing attribute change %q in %+v", path, web.Attributes)
+		}
+		if a.Kind != kind {
+			t.Fatalf("%s: expected kind %s, got %s", path, kind, a.Kind)
+		}
+	}
+	if !got["user_data"].Sensitive {
+		t.Fatalf("expected user_data to be sensitive")
+	}
+	if !got["root_block_device[0].iops"].Unknown {
+		t.Fatalf("expected root_block_device[0].iops to be unknown")
+	}
+	if a := got["instance_type"]; a.Before != "t3.micro" || a.After != "t3.large" {
+		t.Fatalf("unexpected instance_type values: %+v", a)
+	}
+
+	logs, ok := s.ChangeFor("aws_s3_bucket.logs")
+	if !ok || len(logs.Attributes) != 0 {
+		t.Fatalf("expected delete without attribute diff, got %+v", logs)
+	}
+}
+
+func TestParseStats_ExternalDrift(t *testing.T) {
+	b := mustRead(t, filepath.Join("testdata", "plan_external_drift.json"))
+
+	s, err := ParseStats(b)
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+
+	if s.Updates != 1 || s.Deletes != 0 || s.Replaces != 0 {
+		t.Fatalf("unexpected planned counts: %+v", s)
+	}
+	ext := s.External
+	if ext.Updates != 1 || ext.Deletes != 1 || ext.Replaces != 0 || ext.Count() != 2 {
+		t.Fatalf("unexpected external drift counts: %+v", ext)
+	}
+	if len(ext.Resources) != 2 || ext.Resources[1] != "aws_security_group.legacy" {
+		t.Fatalf("unexpected external drift resources: %v", ext.Resources)
+	}
+	c, ok := FindChange(ext.Changes, "aws_instance.web")
+	if !ok || len(c.Attributes) != 1 || c.Attributes[0].Path != "instance_type" {
+		t.Fatalf("unexpected external drift diff: %+v", c)
+	}
+	// aws_instance.web was changed outside of IaC and the plan reverts it: it
+	// stays external drift, but is counted once.
+	if got := DriftedAddresses(s); len(got) != 2 || got[0] != "aws_instance.web" || got[1] != "aws_security_group.legacy" {
+		t.Fatalf("unexpected drifted addresses: %v", got)
+	}
+}
+
+func TestParseStats_NestedSensitive(t *testing.T) {
+	s, err := ParseStats(mustRead(t, filepath.Join("testdata", "plan_nested_sensitive.json")))
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+
+	app, ok := s.ChangeFor("aws_instance.app")
+	if !ok {
+		t.Fatalf("expected change for aws_instance.app, got %+v", s.Changes)
+	}
+	got := map[string]AttributeChange{}
+	for _, a := range app.Attributes {
+		got[a.Path] = a
+	}
+	// Removed and added collections are diffed per element, so only the
+	// values marked sensitive are masked; a type change is masked as a whole.
+	want := map[string]struct {
+		kind      DiffKind
+		sensitive bool
+	}{
+		"cfg.password":    {DiffRemoved, true},
+		"cfg.user":        {DiffRemoved, false},
+		"block[0].secret": {DiffAdded, true},
+		"block[0].name":   {DiffAdded, false},
+		"opts":            {DiffChanged, true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d attribute changes, got %+v", len(want), app.Attributes)
+	}
+	for path, w := range want {
+		if a, ok := got[path]; !ok || a.Kind != w.kind || a.Sensitive != w.sensitive {
+			t.Errorf("%s: got %+v, want kind %s, sensitive=%v", path, a, w.kind, w.sensitive)
+		}
+	}
+}
+
+func TestParseStats_Refactor(t *testing.T) {
+	s, err := ParseStats(mustRead(t, filepath.Join("testdata", "plan_refactor.json")))
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+
+	if s.Moves != 2 || s.Imports != 1 || s.Forgets != 1 {
+		t.Fatalf("Moves/Imports/Forgets = %d/%d/%d, want 2/1/1", s.Moves, s.Imports, s.Forgets)
+	}
+	// Only the moved VPC changes attributes; nothing is destructive.
+	if s.Updates != 1 || s.Deletes != 0 || s.Replaces != 0 {
+		t.Fatalf("unexpected drift counts: updates=%d deletes=%d replaces=%d", s.Updates, s.Deletes, s.Replaces)
+	}
+
+	vpc, _ := FindChange(s.Resources, "module.net.aws_vpc.main")
+	if !vpc.Moved() || vpc.PreviousAddress != "aws_vpc.main" {
+		t.Fatalf("move not decoded: %+v", vpc)
+	}
+	role, _ := FindChange(s.Resources, "aws_iam_role.ci")
+	if !role.Imported() || role.Importing.ID != "ci" || role.Action() != ActionNoOp {
+		t.Fatalf("import not decoded: %+v", role)
+	}
+	legacy, _ := FindChange(s.Resources, "aws_instance.legacy")
+	if legacy.Action() != ActionForget {
+		t.Fatalf("forget classified as %q", legacy.Action())
+	}
+}
+
+func TestParseStats_ActionReasons(t *testing.T) {
+	s, err := ParseStats(mustRead(t, filepath.Join("testdata", "plan_replace_reasons.json")))
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+	if s.Replaces != 4 || s.Deletes != 1 {
+		t.Fatalf("unexpected counts: replaces=%d deletes=%d", s.Replaces, s.Deletes)
+	}
+
+	db, _ := s.ChangeFor("aws_db_instance.main")
+	if db.ActionReason != ReasonReplaceCannotUpdate || !reflect.DeepEqual(db.ReplacePaths, []string{"engine_version"}) {
+		t.Fatalf("unexpected db change: %+v", db)
+	}
+	sg, _ := s.ChangeFor("aws_security_group.app")
+	if want := []string{"ingress[0].cidr_blocks", "name"}; !reflect.DeepEqual(sg.ReplacePaths, want) {
+		t.Fatalf("ReplacePaths = %v, want %v", sg.ReplacePaths, want)
+	}
+	web, _ := s.ChangeFor("aws_instance.web[0]")
+	if web.ActionReason != ReasonReplaceTainted || len(web.ReplacePaths) != 0 {
+		t.Fatalf("unexpected web change: %+v", web)
+	}
+}
+
+func TestParseStats_ReplaceOrder(t *testing.T) {
+	b := mustRead(t, filepath.Join("testdata", "plan_replace_order.json"))
+	s, err := ParseStats(b)
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+	if s.Replaces != 2 || s.CreateBeforeDestroy != 1 || s.DeleteBeforeCreate != 1 || s.Deletes != 1 {
+		t.Fatalf("unexpected counts: replaces=%d cbd=%d dbc=%d deletes=%d", s.Replaces, s.CreateBeforeDestroy, s.DeleteBeforeCreate, s.Deletes)
+	}
+	want := []string{"aws_instance.web", "aws_instance.web (deposed object 1a2b3c4d)", "aws_db_instance.main"}
+	if !reflect.DeepEqual(s.DriftedResources, want) {
+		t.Fatalf("DriftedResources = %v, want %v", s.DriftedResources, want)
+	}
+	if len(s.Deposed) != 1 || s.Deposed[0].Deposed != "1a2b3c4d" {
+		t.Fatalf("unexpected deposed objects: %+v", s.Deposed)
+	}
+
+	current, _ := s.ChangeFor("aws_instance.web")
+	if current.IsDeposed() || !current.CreateBeforeDestroy() {
+		t.Fatalf("unexpected current object: %+v", current)
+	}
+	deposed, ok := s.ChangeFor(want[1])
+	if !ok || deposed.Action() != ActionDelete || deposed.CreateBeforeDestroy() {
+		t.Fatalf("unexpected deposed object: %+v", deposed)
+	}
+}
+
+func TestParseStats_DataSources(t *testing.T) {
+	b := mustRead(t, filepath.Join("testdata", "plan_data_sources.json"))
+	s, err := ParseStats(b)
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+	if s.TotalResources != 3 || s.ManagedResources != 1 || s.DataSources != 2 {
+		t.Fatalf("unexpected resource counts: total=%d managed=%d data=%d", s.TotalResources, s.ManagedResources, s.DataSources)
+	}
+	if DriftCount(s) != 1 || s.External.Count() != 0 {
+		t.Fatalf("data sources must not count as drift: planned=%d external=%d", DriftCount(s), s.External.Count())
+	}
+	if len(s.DeferredReads) != 1 || s.DeferredReads[0].ActionReason != ReasonReadConfigUnknown {
+		t.Fatalf("unexpected deferred reads: %+v", s.DeferredReads)
+	}
+	if len(s.DataChanges) != 1 || s.DataChanges[0].Address != "data.aws_ami.ubuntu" {
+		t.Fatalf("unexpected data changes: %+v", s.DataChanges)
+	}
+}
+
+func TestParseStats_Outputs(t *testing.T) {
+	b := mustRead(t, filepath.Join("testdata", "plan_outputs.json"))
+	s, err := ParseStats(b)
+	if err != nil {
+		t.Fatalf("ParseStats error: %v", err)
+	}
+	if len(s.Outputs) != 3 {
+		t.Fatalf("expected 3 changed outputs (no-op skipped), got %+v", s.Outputs)
+	}
+	if DriftCount(s) != 0 {
+		t.Fatalf("outputs must not count as drift, got %d", DriftCount(s))
+	}
+
+	secret, endpoint, network := s.Outputs[0], s.Outputs[1], s.Outputs[2]
+	if secret.Name != "db_password" || len(secret.Attributes) != 1 || !secret.Attributes[0].Sensitive {
+		t.Fatalf("sensitive output not marked: %+v", secret)
+	}
+	if endpoint.Action() != ActionUpdate || len(endpoint.Attributes) != 1 || endpoint.Attributes[0].Path != "endpoint" || endpoint.Attributes[0].After != "10.0.0.42" {
+		t.Fatalf("unexpected endpoint output: %+v", endpoint)
+	}
+	if len(network.Attributes) != 1 || network.Attributes[0].Path != "network.subnets[1]" {
+		t.Fatalf("unexpected network output: %+v", network)
+	}
+
+	if revealed := RevealSensitive(s); revealed.Outputs[0].Attributes[0].Sensitive {
+		t.Fatalf("RevealSensitive kept the output masked")
+	}
+	if masked := MaskSensitive(s, []string{"endpoint"}); !masked.Outputs[1].Attributes[0].Sensitive {
+		t.Fatalf("MaskSensitive did not mask the endpoint output")
+	}
+}
@@ -0,0 +1,19 @@
+package plan
+
+import (
+	"os/exec"
+	"time"
+)
+
+// GracePeriod is how long a cancelled runner (on timeout or interrupt) gets
+// to release the state lock and exit after being interrupted, before its
+// process group is killed.
+var GracePeriod = 30 * time.Second
+
+// run runs a command built by command and, once it has exited, releases its
+// process group (see releaseGroup).
+func run(cmd *exec.Cmd) error {
+	err := cmd.Run()
+	releaseGroup(cmd)
+	return err
+}
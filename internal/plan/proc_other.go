@@ -0,0 +1,16 @@
+//go:build !unix
+
+package plan
+
+import "os/exec"
+
+// setGracefulCancel keeps the default cancellation (killing the runner):
+// process groups and SIGINT are not available on this platform.
+func setGracefulCancel(cmd *exec.Cmd) {}
+
+// releaseGroup is a no-op on this platform.
+func releaseGroup(cmd *exec.Cmd) {}
+
+// KillProcesses is a no-op on this platform: cancelled runners are killed
+// right away.
+func KillProcesses() {}
@@ -0,0 +1,81 @@
+//go:build unix
+
+package plan
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// stopping holds the process groups of cancelled runners that have not been
+// killed yet, with the timers that kill them after the grace period.
+var stopping = struct {
+	sync.Mutex
+	pgids map[int]*time.Timer
+}{pgids: map[int]*time.Timer{}}
+
+// setGracefulCancel runs cmd in its own process group (so the runner and its
+// provider plugins are signalled together, and a terminal's Ctrl-C reaches
+// drift-checker only) and makes cancelling its context interrupt the group
+// with SIGINT instead of killing the runner. The group is killed GracePeriod
+// later if it is still running.
+func setGracefulCancel(cmd *exec.Cmd) {
+	grace := GracePeriod
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		stopping.Lock()
+		stopping.pgids[pgid] = time.AfterFunc(grace, func() { killGroup(pgid) })
+		stopping.Unlock()
+
+		if err := syscall.Kill(-pgid, syscall.SIGINT); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+		return nil
+	}
+	// Bounds the wait for the runner (and the pipes of its children) to close.
+	cmd.WaitDelay = grace
+}
+
+// killGroup kills the process group pgid if it belongs to a cancelled runner
+// and forgets it, so that the group id is never signalled again once it may
+// have been reused.
+func killGroup(pgid int) {
+	stopping.Lock()
+	timer, ok := stopping.pgids[pgid]
+	delete(stopping.pgids, pgid)
+	stopping.Unlock()
+	if ok {
+		timer.Stop()
+		_ = syscall.Kill(-pgid, syscall.SIGKILL)
+	}
+}
+
+// releaseGroup is called once cmd has exited. If it was cancelled, any
+// plugins it left behind in its process group are killed right away and the
+// pending grace-period kill is dropped: the group id may be reused as soon as
+// the group is empty.
+func releaseGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		killGroup(cmd.Process.Pid)
+	}
+}
+
+// KillProcesses immediately kills the process groups of cancelled runners
+// that are still in their grace period, including plugins left behind by a
+// runner that already exited. It is meant for exiting drift-checker (normally
+// or on a second interrupt), as no runner must outlive it.
+func KillProcesses() {
+	stopping.Lock()
+	pgids := make([]int, 0, len(stopping.pgids))
+	for pgid := range stopping.pgids {
+		pgids = append(pgids, pgid)
+	}
+	stopping.Unlock()
+	for _, pgid := range pgids {
+		killGroup(pgid)
+	}
+}
@@ -0,0 +1,74 @@
+//go:build unix
+
+package plan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// scriptRunner writes a shell script standing in for a runner.
+func scriptRunner(t *testing.T, script string) RunnerKind {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "fake-tofu")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return RunnerKind(bin)
+}
+
+func TestCommand_GracefulCancel(t *testing.T) {
+	old := GracePeriod
+	t.Cleanup(func() {
+		GracePeriod = old
+		KillProcesses()
+	})
+	GracePeriod = 5 * time.Second
+
+	mark := filepath.Join(t.TempDir(), "interrupted")
+	t.Setenv("MARK", mark)
+	runner := scriptRunner(t, `trap 'echo released > "$MARK"; exit 1' INT
+sleep 30 &
+wait
+`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := run(command(ctx, runner, t.TempDir(), "plan")); err == nil {
+		t.Fatal("expected the cancelled runner to fail")
+	}
+	if d := time.Since(start); d > 4*time.Second {
+		t.Fatalf("runner was not interrupted, but waited for (%v)", d)
+	}
+	if _, err := os.Stat(mark); err != nil {
+		t.Fatalf("runner did not receive SIGINT: %v", err)
+	}
+	stopping.Lock()
+	pending := len(stopping.pgids)
+	stopping.Unlock()
+	if pending != 0 {
+		t.Fatalf("the exited runner's process group is still scheduled to be killed (%d pending)", pending)
+	}
+}
+
+func TestCommand_KilledAfterGracePeriod(t *testing.T) {
+	old := GracePeriod
+	t.Cleanup(func() { GracePeriod = old })
+	GracePeriod = 300 * time.Millisecond
+
+	runner := scriptRunner(t, "trap '' INT\nsleep 30\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := command(ctx, runner, t.TempDir(), "plan").Run(); err == nil {
+		t.Fatal("expected the killed runner to fail")
+	}
+	if d := time.Since(start); d < 500*time.Millisecond || d > 10*time.Second {
+		t.Fatalf("runner stopped after %v, want after the grace period", d)
+	}
+}
@@ -0,0 +1,106 @@
+package plan
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ProgressDone is the type of the final ProgressEvent of a plan, sent once its
+// UI output ended (whether the plan succeeded or not).
+const ProgressDone = "done"
+
+// ProgressEvent is a message of the runner's -json UI output, delivered while
+// the plan runs (see PlanOptions.Progress).
+type ProgressEvent struct {
+	Type    string        // UI message type, e.g. "refresh_start", "refresh_complete", "apply_progress"
+	Address string        // resource address of hook messages
+	Time    time.Time     // @timestamp of the message (receipt time if missing)
+	Elapsed time.Duration // elapsed_seconds of progress messages
+	Message string        // human-readable @message
+}
+
+// ResourceTiming is how long the refresh of one resource took (or has taken
+// so far, when InFlight).
+type ResourceTiming struct {
+	Address  string
+	Duration time.Duration
+	InFlight bool
+}
+
+// Progress accumulates ProgressEvents into refresh statistics. It is safe for
+// concurrent use.
+type Progress struct {
+	mu        sync.Mutex
+	start     time.Time
+	last      time.Time
+	refreshed int
+	started   map[string]time.Time
+	durations map[string]time.Duration
+}
+
+func NewProgress() *Progress {
+	return &Progress{started: map[string]time.Time{}, durations: map[string]time.Duration{}}
+}
+
+// Observe records e.
+func (p *Progress) Observe(e ProgressEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.start.IsZero() {
+		p.start = e.Time
+	}
+	if e.Time.After(p.last) {
+		p.last = e.Time
+	}
+	switch e.Type {
+	case "refresh_start":
+		p.started[e.Address] = e.Time
+	case "refresh_complete":
+		if t, ok := p.started[e.Address]; ok {
+			p.durations[e.Address] = e.Time.Sub(t)
+			delete(p.started, e.Address)
+		}
+		p.refreshed++
+	}
+}
+
+// ProgressSnapshot is the state of a Progress at a point in time.
+type ProgressSnapshot struct {
+	Refreshed int              // resources whose refresh completed
+	InFlight  int              // resources being refreshed
+	Elapsed   time.Duration    // since the first event
+	Slowest   []ResourceTiming // slowest refreshes, in-flight ones included, longest first
+}
+
+// Snapshot returns the statistics as of now, listing up to n slowest resources.
+func (p *Progress) Snapshot(now time.Time, n int) ProgressSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now.Before(p.last) {
+		now = p.last
+	}
+	s := ProgressSnapshot{Refreshed: p.refreshed, InFlight: len(p.started)}
+	if !p.start.IsZero() {
+		s.Elapsed = now.Sub(p.start)
+	}
+
+	timings := make([]ResourceTiming, 0, len(p.durations)+len(p.started))
+	for addr, d := range p.durations {
+		timings = append(timings, ResourceTiming{Address: addr, Duration: d})
+	}
+	for addr, t := range p.started {
+		timings = append(timings, ResourceTiming{Address: addr, Duration: now.Sub(t), InFlight: true})
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Duration != timings[j].Duration {
+			return timings[i].Duration > timings[j].Duration
+		}
+		return timings[i].Address < timings[j].Address
+	})
+	if len(timings) > n {
+		timings = timings[:n]
+	}
+	s.Slowest = timings
+	return s
+}
@@ -0,0 +1,52 @@
+package plan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "plan_ui_refresh.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	p := NewProgress()
+	if err := decodeUIStream(f, func(m uiMessage) { p.Observe(m.progressEvent(time.Time{})) }); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 17, 10, 0, 10, 0, time.UTC)
+	s := p.Snapshot(now, 2)
+	if s.Refreshed != 2 || s.InFlight != 1 || s.Elapsed != 10*time.Second {
+		t.Fatalf("unexpected snapshot: %+v", s)
+	}
+	want := []ResourceTiming{
+		{Address: "aws_db_instance.main", Duration: 6 * time.Second, InFlight: true},
+		{Address: "aws_s3_bucket.logs", Duration: 4 * time.Second},
+	}
+	if !reflect.DeepEqual(s.Slowest, want) {
+		t.Fatalf("Slowest = %+v, want %+v", s.Slowest, want)
+	}
+}
+
+func TestMakeRefreshOnlyPlanJSON_Progress(t *testing.T) {
+	runner, _ := fakeRunner(t)
+	t.Setenv("FAKE_UI", filepath.Join("testdata", "plan_ui_refresh.jsonl"))
+
+	var types []string
+	_, err := MakeRefreshOnlyPlanJSON(context.Background(), runner, t.TempDir(), PlanOptions{
+		Progress: func(e ProgressEvent) { types = append(types, e.Type) },
+	})
+	if err != nil {
+		t.Fatalf("MakeRefreshOnlyPlanJSON error: %v", err)
+	}
+	if len(types) != 7 || types[1] != "refresh_start" || types[6] != ProgressDone {
+		t.Fatalf("unexpected progress events: %v", types)
+	}
+}
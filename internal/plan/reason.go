@@ -0,0 +1,53 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Action reasons (the plan's action_reason) that explain why a resource is
+// replaced, deleted or read.
+const (
+	ReasonReplaceCannotUpdate = "replace_because_cannot_update"
+	ReasonReplaceTainted      = "replace_because_tainted"
+	ReasonReplaceByRequest    = "replace_by_request"
+	ReasonReplaceByTriggers   = "replace_by_triggers"
+	ReasonDeleteNoConfig      = "delete_because_no_resource_config"
+	ReasonDeleteNoModule      = "delete_because_no_module"
+	ReasonDeleteRepetition    = "delete_because_wrong_repetition"
+	ReasonDeleteCountIndex    = "delete_because_count_index"
+	ReasonDeleteEachKey       = "delete_because_each_key"
+	ReasonDeleteNoMoveTarget  = "delete_because_no_move_target"
+	// Reads of data sources deferred until apply.
+	ReasonReadConfigUnknown     = "read_because_config_unknown"
+	ReasonReadDependencyPending = "read_because_dependency_pending"
+	ReasonReadCheckNested       = "read_because_check_nested"
+)
+
+// replacePaths converts the plan's replace_paths (lists of attribute names
+// and indexes) into attribute paths in the notation of AttributeChange.Path,
+// e.g. engine_version or ingress[0].cidr_blocks.
+func replacePaths(raw [][]any) []string {
+	out := make([]string, 0, len(raw))
+	for _, steps := range raw {
+		path := ""
+		for _, step := range steps {
+			switch s := step.(type) {
+			case string:
+				path = joinKey(path, s)
+			case json.Number:
+				if i, err := s.Int64(); err == nil {
+					path = joinIndex(path, int(i))
+				}
+			case float64:
+				path = joinIndex(path, int(s))
+			default:
+				path += fmt.Sprintf("[%v]", s)
+			}
+		}
+		if path != "" {
+			out = append(out, path)
+		}
+	}
+	return out
+}
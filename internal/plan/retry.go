@@ -0,0 +1,67 @@
+package plan
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// RetryPolicy retries runner commands that failed for a transient reason
+// (see ErrorCategory.Retryable) with exponential backoff.
+type RetryPolicy struct {
+	Retries  int           // attempts after the first; 0 disables retrying
+	Delay    time.Duration // wait before the first retry, doubled for every further one
+	MaxDelay time.Duration // upper bound of the wait; 0 means no bound
+	// OnRetry, when set, is called before waiting for retry number attempt.
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// Do calls fn until it succeeds, fails with a non-retryable error or the
+// retries are exhausted, and returns its last error. It never waits past the
+// deadline of ctx: when the next wait would end after it, or ctx is done, the
+// last error is returned right away.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > p.Retries || !Category(err).Retryable() || ctx.Err() != nil {
+			return err
+		}
+
+		wait := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, wait, err)
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// backoff returns the wait before retry number attempt (starting at 1).
+// Without MaxDelay the doubling saturates at the longest time.Duration
+// instead of overflowing.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.Delay
+	for i := 1; i < attempt; i++ {
+		if wait > math.MaxInt64/2 {
+			wait = math.MaxInt64
+			break
+		}
+		wait *= 2
+		if p.MaxDelay > 0 && wait >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+	return wait
+}
@@ -0,0 +1,90 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	cases := map[string]ErrorCategory{
+		"Error: Error acquiring the state lock\n\nLock Info:\n  ID: 1234":  CategoryLocked,
+		"Error: reading EC2 instance: ThrottlingException: Rate exceeded":  CategoryThrottled,
+		"dial tcp: lookup sts.amazonaws.com: no such host":                 CategoryNetwork,
+		"Error: No valid credential sources found":                         CategoryAuth,
+		"Error: Reference to undeclared input variable":                    CategoryConfig,
+		"Error: Backend initialization required, please run \"tofu init\"": CategoryNotInitialized,
+		"Error: something unexpected":                                      CategoryUnknown,
+	}
+	for stderr, want := range cases {
+		if got := classify(stderr); got != want {
+			t.Errorf("classify(%q) = %q, want %q", stderr, got, want)
+		}
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	locked := &RunnerError{Runner: RunnerTofu, Op: "plan", Category: CategoryLocked, Err: errors.New("exit status 1")}
+	auth := &RunnerError{Runner: RunnerTofu, Op: "plan", Category: CategoryAuth, Err: errors.New("exit status 1")}
+
+	var waits []time.Duration
+	p := RetryPolicy{
+		Retries:  3,
+		Delay:    time.Millisecond,
+		MaxDelay: 3 * time.Millisecond,
+		OnRetry:  func(_ int, wait time.Duration, _ error) { waits = append(waits, wait) },
+	}
+
+	calls := 0
+	err := p.Do(context.Background(), func() error {
+		if calls++; calls < 4 {
+			return locked
+		}
+		return nil
+	})
+	if err != nil || calls != 4 {
+		t.Fatalf("Do() = %v after %d calls, want success after 4", err, calls)
+	}
+	if want := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}; len(waits) != 3 || waits[0] != want[0] || waits[1] != want[1] || waits[2] != want[2] {
+		t.Fatalf("waits = %v, want %v", waits, want)
+	}
+
+	calls = 0
+	if err := p.Do(context.Background(), func() error { calls++; return auth }); err != auth || calls != 1 {
+		t.Fatalf("non-retryable error retried: %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	if err := p.Do(context.Background(), func() error { calls++; return locked }); err != locked || calls != 4 {
+		t.Fatalf("retries not bounded: %v after %d calls", err, calls)
+	}
+
+	// A wait that would outlast the deadline is not started.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	calls = 0
+	p.Delay, p.MaxDelay = 2*time.Hour, 0
+	if err := p.Do(ctx, func() error { calls++; return locked }); err != locked || calls != 1 {
+		t.Fatalf("waited past the deadline: %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryPolicy_BackoffSaturates(t *testing.T) {
+	p := RetryPolicy{Delay: time.Second}
+	prev := p.backoff(1)
+	for attempt := 2; attempt <= 100; attempt++ {
+		wait := p.backoff(attempt)
+		if wait < prev {
+			t.Fatalf("backoff(%d) = %v, less than backoff(%d) = %v", attempt, wait, attempt-1, prev)
+		}
+		prev = wait
+	}
+	if prev != time.Duration(math.MaxInt64) {
+		t.Fatalf("backoff(100) = %v, want the longest duration", prev)
+	}
+	if p.MaxDelay = time.Minute; p.backoff(100) != time.Minute {
+		t.Fatalf("MaxDelay not applied: %v", p.backoff(100))
+	}
+}
@@ -5,18 +5,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type RunnerKind string
 
 const (
-	RunnerTofu      RunnerKind = "tofu"
-	RunnerTerraform RunnerKind = "terraform"
+	RunnerTofu       RunnerKind = "tofu"
+	RunnerTerraform  RunnerKind = "terraform"
+	RunnerTerragrunt RunnerKind = "terragrunt"
 )
 
+// terragruntConfig is the file marking a Terragrunt-managed working directory.
+const terragruntConfig = "terragrunt.hcl"
+
 func SelectRunner() (RunnerKind, error) {
 	if _, err := exec.LookPath("tofu"); err == nil {
 		return RunnerTofu, nil
@@ -27,46 +35,238 @@ func SelectRunner() (RunnerKind, error) {
 	return "", errors.New("neither 'tofu' nor 'terraform' found in PATH")
 }
 
+// SelectRunnerFor picks the runner for the working directory path: Terragrunt
+// when path contains a terragrunt.hcl (and terragrunt is installed), otherwise
+// whatever fallback returns (normally SelectRunner).
+func SelectRunnerFor(path string, fallback func() (RunnerKind, error)) (RunnerKind, error) {
+	if _, err := os.Stat(filepath.Join(path, terragruntConfig)); err == nil {
+		if _, err := exec.LookPath(string(RunnerTerragrunt)); err == nil {
+			return RunnerTerragrunt, nil
+		}
+	}
+	return fallback()
+}
+
+// ParseRunner validates an explicit runner name. An empty name and "auto" both
+// mean automatic selection and yield "".
+func ParseRunner(name string) (RunnerKind, error) {
+	switch k := RunnerKind(name); k {
+	case "", "auto":
+		return "", nil
+	case RunnerTofu, RunnerTerraform, RunnerTerragrunt:
+		return k, nil
+	}
+	return "", fmt.Errorf("unsupported runner %q (use tofu|terraform|terragrunt|auto)", name)
+}
+
+// command builds the invocation of a runner subcommand in the working
+// directory path. Terraform and OpenTofu use -chdir; Terragrunt runs in path
+// and non-interactively, set through the environment (TG_NON_INTERACTIVE, and
+// TERRAGRUNT_NON_INTERACTIVE for releases before the TG_ prefix) since its
+// --terragrunt-* flags are deprecated. Cancelling ctx interrupts the runner
+// and kills it only after GracePeriod.
+func command(ctx context.Context, runner RunnerKind, path string, args ...string) *exec.Cmd {
+	if runner != RunnerTerragrunt {
+		args = append([]string{"-chdir=" + path}, args...)
+	}
+	cmd := exec.CommandContext(ctx, string(runner), args...)
+	if runner == RunnerTerragrunt {
+		cmd.Dir = path
+		cmd.Env = append(cmd.Environ(), "TG_NON_INTERACTIVE=true", "TERRAGRUNT_NON_INTERACTIVE=true")
+	}
+	setGracefulCancel(cmd)
+	return cmd
+}
+
+// PlanOptions customizes the refresh-only plan of a single working directory.
+type PlanOptions struct {
+	VarFiles      []string      // -var-file arguments, relative to the working directory
+	Vars          []string      // -var arguments ("name=value")
+	Targets       []string      // -target arguments (resource addresses)
+	NoLock        bool          // -lock=false
+	LockTimeout   time.Duration // -lock-timeout; 0 uses the runner default
+	Parallelism   int           // -parallelism; 0 uses the runner default
+	Init          bool          // run init (see Init) before the first runner command
+	BackendConfig []string      // -backend-config arguments of init (files or key=value)
+	Workspace     string        // selected via TF_WORKSPACE when set
+	Env           []string      // extra KEY=VALUE environment entries for the runner
+	KeepPlan      string        // copy the binary plan to this path (relative to the current directory)
+	// Progress, when set, receives the plan's -json UI messages as they
+	// arrive, followed by a ProgressDone event. It is called from a single
+	// goroutine per plan.
+	Progress func(ProgressEvent)
+}
+
+// planArgs returns the pass-through arguments of the plan command.
+func (o PlanOptions) planArgs() []string {
+	var args []string
+	for _, f := range o.VarFiles {
+		args = append(args, "-var-file="+f)
+	}
+	for _, v := range o.Vars {
+		args = append(args, "-var="+v)
+	}
+	for _, t := range o.Targets {
+		args = append(args, "-target="+t)
+	}
+	if o.NoLock {
+		args = append(args, "-lock=false")
+	}
+	if o.LockTimeout > 0 {
+		args = append(args, "-lock-timeout="+o.LockTimeout.String())
+	}
+	if o.Parallelism > 0 {
+		args = append(args, "-parallelism="+strconv.Itoa(o.Parallelism))
+	}
+	return args
+}
+
+// env returns the environment of cmd (see command) extended with the options.
+func (o PlanOptions) env(cmd *exec.Cmd) []string {
+	env := append(cmd.Environ(), o.Env...)
+	if o.Workspace != "" {
+		env = append(env, "TF_WORKSPACE="+o.Workspace)
+	}
+	return env
+}
+
 // MakeRefreshOnlyPlanJSON always uses two-step method for reliability:
-//   <runner> -chdir=<path> plan -refresh-only -out=drift-checker.plan
-//   <runner> -chdir=<path> show -json drift-checker.plan
 //
-// This works across Terraform and OpenTofu versions without depending on streaming -json.
-func MakeRefreshOnlyPlanJSON(ctx context.Context, runner RunnerKind, path string) ([]byte, error) {
-	chdir := []string{"-chdir=" + path}
+//	<runner> -chdir=<path> plan -refresh-only -json [-var-file=... -var=... -target=... -lock...] -out=<tmp>/drift-checker.plan
+//	<runner> -chdir=<path> show -json <tmp>/drift-checker.plan
+//
+// (Terragrunt: terragrunt plan -refresh-only ..., run in <path>.)
+//
+// This works across Terraform and OpenTofu versions without depending on streaming -json
+// for the plan itself; the plan's -json UI output is only used for progress (streamed
+// to opts.Progress) and diagnostics, which a failing plan returns in a *RunnerError.
+// The binary plan is written to a private temporary directory (never into path), so
+// concurrent scans of the same directory do not collide, and it is removed on return.
+// When opts.KeepPlan is set, the plan is copied there first.
+func MakeRefreshOnlyPlanJSON(ctx context.Context, runner RunnerKind, path string, opts PlanOptions) ([]byte, error) {
+	if opts.Init {
+		if err := Init(ctx, runner, path, opts); err != nil {
+			return nil, err
+		}
+	}
 
-	planFile := "drift-checker.plan"
-	planPath := filepath.Join(path, planFile)
+	tmp, err := newTempDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plan directory: %w", err)
+	}
+	defer removeTempDir(tmp)
+	planFile := filepath.Join(tmp, "drift-checker.plan")
 
-	// 1) plan -refresh-only -out=...
-	planCmd := exec.CommandContext(
-		ctx,
-		string(runner),
-		append(chdir, "plan", "-refresh-only", "-out="+planFile)...,
-	)
-	planCmd.Env = os.Environ()
+	// 1) plan -refresh-only -json -out=...
+	planArgs := append([]string{"plan", "-refresh-only", "-json"}, opts.planArgs()...)
+	planCmd := command(ctx, runner, path, append(planArgs, "-out="+planFile)...)
+	planCmd.Env = opts.env(planCmd)
 
 	var stderrPlan bytes.Buffer
 	planCmd.Stderr = &stderrPlan
-	if err := planCmd.Run(); err != nil {
-		return nil, fmt.Errorf("%s plan failed: %v\n%s", runner, err, stderrPlan.String())
+	diags, err := runUIStream(planCmd, opts.Progress)
+	if err != nil {
+		return nil, newRunnerError(runner, "plan", err, stderrPlan.String(), diags...)
+	}
+	if opts.KeepPlan != "" {
+		if err := copyFile(planFile, opts.KeepPlan); err != nil {
+			return nil, fmt.Errorf("failed to keep plan: %w", err)
+		}
 	}
-	defer func() { _ = os.Remove(planPath) }()
 
 	// 2) show -json <planFile>
-	showCmd := exec.CommandContext(
-		ctx,
-		string(runner),
-		append(chdir, "show", "-json", planFile)...,
-	)
-	showCmd.Env = os.Environ()
+	return ShowPlanJSON(ctx, runner, path, planFile, opts)
+}
+
+// runUIStream runs cmd, decoding its -json UI output while it runs: messages
+// are passed to progress (if set) and diagnostics are collected.
+func runUIStream(cmd *exec.Cmd, progress func(ProgressEvent)) ([]Diagnostic, error) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+
+	var diags []Diagnostic
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = decodeUIStream(pr, func(m uiMessage) {
+			if m.Type == "diagnostic" && m.Diagnostic != nil {
+				diags = append(diags, *m.Diagnostic)
+			}
+			if progress != nil {
+				progress(m.progressEvent(time.Now()))
+			}
+		})
+	}()
+
+	err := run(cmd)
+	pw.Close()
+	<-done
+	if progress != nil {
+		progress(ProgressEvent{Type: ProgressDone, Time: time.Now()})
+	}
+	return diags, err
+}
+
+// ShowPlanJSON renders the binary plan file planFile as JSON with
+// `<runner> show -json`, run in the working directory path (the plan's root
+// module, whose providers must be initialized). A relative planFile is
+// resolved against the current directory, not path.
+func ShowPlanJSON(ctx context.Context, runner RunnerKind, path, planFile string, opts PlanOptions) ([]byte, error) {
+	abs, err := filepath.Abs(planFile)
+	if err != nil {
+		return nil, err
+	}
+	showCmd := command(ctx, runner, path, "show", "-json", abs)
+	showCmd.Env = opts.env(showCmd)
 
 	var stdoutShow, stderrShow bytes.Buffer
 	showCmd.Stdout = &stdoutShow
 	showCmd.Stderr = &stderrShow
-	if err := showCmd.Run(); err != nil {
-		return nil, fmt.Errorf("%s show -json failed: %v\n%s", runner, err, stderrShow.String())
+	if err := run(showCmd); err != nil {
+		return nil, newRunnerError(runner, "show -json", err, stderrShow.String())
 	}
 
 	return stdoutShow.Bytes(), nil
-}
\ No newline at end of file
+}
+
+// ListWorkspaces returns the workspaces of the working directory path, as
+// reported by `<runner> workspace list`. opts.Workspace is ignored: the list
+// does not depend on the selected workspace, and TF_WORKSPACE naming a
+// workspace that does not exist would make the command fail.
+func ListWorkspaces(ctx context.Context, runner RunnerKind, path string, opts PlanOptions) ([]string, error) {
+	opts.Workspace = ""
+	if opts.Init {
+		if err := Init(ctx, runner, path, opts); err != nil {
+			return nil, err
+		}
+	}
+	cmd := command(ctx, runner, path, "workspace", "list")
+	cmd.Env = opts.env(cmd)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := run(cmd); err != nil {
+		return nil, newRunnerError(runner, "workspace list", err, stderr.String())
+	}
+	return parseWorkspaceList(stdout.String()), nil
+}
+
+// parseWorkspaceList parses `workspace list` output, where the selected
+// workspace is prefixed with "* ".
+func parseWorkspaceList(out string) []string {
+	var workspaces []string
+	for _, line := range strings.Split(out, "\n") {
+		name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if name != "" {
+			workspaces = append(workspaces, name)
+		}
+	}
+	return workspaces
+}
+
+// IsBinaryPlan reports whether b looks like a binary plan file (saved with
+// -out), which is a zip archive, rather than plan JSON.
+func IsBinaryPlan(b []byte) bool {
+	return bytes.HasPrefix(b, []byte("PK\x03\x04"))
+}
@@ -0,0 +1,225 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRunner writes a shell script standing in for tofu/terraform: "plan"
+// prints the UI stream file $FAKE_UI, writes the -out file and records its
+// path (or fails as uninitialized when FAKE_NOT_INIT is set), "init" appends
+// its arguments to <record>.init, "show" prints a clean plan and "version"
+// prints its version.
+func fakeRunner(t *testing.T) (RunnerKind, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script runner")
+	}
+	dir := t.TempDir()
+	record := filepath.Join(dir, "out-path")
+	script := `#!/bin/sh
+for a in "$@"; do
+  case "$a" in
+    init) echo "$*" >> "` + record + `.init"; exit 0 ;;
+    plan) if [ -n "$FAKE_NOT_INIT" ]; then echo 'Error: Backend initialization required, please run "tofu init"' >&2; exit 1; fi
+      if [ -n "$FAKE_UI" ]; then cat "$FAKE_UI"; fi ;;
+    -out=*) f="${a#-out=}"; echo plan > "$f"; echo "$f" > "` + record + `" ;;
+    show) echo '{"format_version":"1.2","resource_changes":[]}'; exit 0 ;;
+    version) echo '{"terraform_version":"1.8.2","platform":"linux_amd64"}'; exit 0 ;;
+  esac
+done
+`
+	bin := filepath.Join(dir, "fake-tofu")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return RunnerKind(bin), record
+}
+
+func TestMakeRefreshOnlyPlanJSON_TempPlan(t *testing.T) {
+	runner, record := fakeRunner(t)
+	work := t.TempDir()
+	keep := filepath.Join(t.TempDir(), "plans", "drift.tfplan")
+
+	out, err := MakeRefreshOnlyPlanJSON(context.Background(), runner, work, PlanOptions{KeepPlan: keep})
+	if err != nil {
+		t.Fatalf("MakeRefreshOnlyPlanJSON error: %v", err)
+	}
+	if !strings.Contains(string(out), "resource_changes") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	b, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planFile := strings.TrimSpace(string(b))
+	if strings.HasPrefix(planFile, work) {
+		t.Fatalf("plan written into the working directory: %s", planFile)
+	}
+	if _, err := os.Stat(filepath.Dir(planFile)); !os.IsNotExist(err) {
+		t.Fatalf("temporary plan directory not removed: %v", err)
+	}
+	if entries, _ := os.ReadDir(work); len(entries) != 0 {
+		t.Fatalf("working directory not left untouched: %v", entries)
+	}
+
+	fi, err := os.Stat(keep)
+	if err != nil {
+		t.Fatalf("kept plan missing: %v", err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Fatalf("kept plan has mode %v, want 0600", fi.Mode().Perm())
+	}
+}
+
+func TestCommand(t *testing.T) {
+	for _, tc := range []struct {
+		runner RunnerKind
+		want   []string
+		dir    string
+	}{
+		{RunnerTofu, []string{"tofu", "-chdir=infra", "show", "-json", "x.plan"}, ""},
+		{RunnerTerraform, []string{"terraform", "-chdir=infra", "show", "-json", "x.plan"}, ""},
+		{RunnerTerragrunt, []string{"terragrunt", "show", "-json", "x.plan"}, "infra"},
+	} {
+		cmd := command(context.Background(), tc.runner, "infra", "show", "-json", "x.plan")
+		if strings.Join(cmd.Args, " ") != strings.Join(tc.want, " ") || cmd.Dir != tc.dir {
+			t.Errorf("%s: got %q in %q, want %q in %q", tc.runner, cmd.Args, cmd.Dir, tc.want, tc.dir)
+		}
+		nonInteractive := slices.Contains(cmd.Env, "TG_NON_INTERACTIVE=true") && slices.Contains(cmd.Env, "TERRAGRUNT_NON_INTERACTIVE=true")
+		if nonInteractive != (tc.runner == RunnerTerragrunt) {
+			t.Errorf("%s: non-interactive environment = %v", tc.runner, nonInteractive)
+		}
+	}
+}
+
+func TestParseRunner(t *testing.T) {
+	for name, want := range map[string]RunnerKind{"": "", "auto": "", "tofu": RunnerTofu, "terragrunt": RunnerTerragrunt} {
+		got, err := ParseRunner(name)
+		if err != nil || got != want {
+			t.Errorf("ParseRunner(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseRunner("pulumi"); err == nil {
+		t.Error("expected an error for an unsupported runner")
+	}
+}
+
+func TestDetectVersion(t *testing.T) {
+	runner, _ := fakeRunner(t)
+	v, err := DetectVersion(context.Background(), runner, t.TempDir(), PlanOptions{})
+	if err != nil {
+		t.Fatalf("DetectVersion error: %v", err)
+	}
+	if v.Kind != runner || v.Version != "1.8.2" || v.Platform != "linux_amd64" {
+		t.Fatalf("unexpected version: %+v", v)
+	}
+}
+
+func TestPlanOptionsArgs(t *testing.T) {
+	opts := PlanOptions{
+		VarFiles:    []string{"prod.tfvars"},
+		Vars:        []string{"region=eu-west-1"},
+		Targets:     []string{"module.db"},
+		NoLock:      true,
+		LockTimeout: 90 * time.Second,
+		Parallelism: 4,
+	}
+	want := "-var-file=prod.tfvars -var=region=eu-west-1 -target=module.db -lock=false -lock-timeout=1m30s -parallelism=4"
+	if got := strings.Join(opts.planArgs(), " "); got != want {
+		t.Fatalf("planArgs() = %q, want %q", got, want)
+	}
+	if args := (PlanOptions{}).planArgs(); len(args) != 0 {
+		t.Fatalf("expected no arguments by default, got %q", args)
+	}
+}
+
+func TestParseWorkspaceList(t *testing.T) {
+	got := parseWorkspaceList("  default\n* prod\n  staging\n\n")
+	if strings.Join(got, ",") != "default,prod,staging" {
+		t.Fatalf("parseWorkspaceList = %q", got)
+	}
+}
+
+func TestInit_Once(t *testing.T) {
+	runner, record := fakeRunner(t)
+	work := t.TempDir()
+	opts := PlanOptions{Init: true, BackendConfig: []string{"prod.tfbackend", "key=drift"}, Workspace: "prod"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := MakeRefreshOnlyPlanJSON(context.Background(), runner, work, opts); err != nil {
+			t.Fatalf("MakeRefreshOnlyPlanJSON error: %v", err)
+		}
+	}
+
+	b, err := os.ReadFile(record + ".init")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("init ran %d times, want once: %q", len(lines), lines)
+	}
+	want := "-chdir=" + work + " init -input=false -upgrade=false -backend-config=prod.tfbackend -backend-config=key=drift"
+	if lines[0] != want {
+		t.Fatalf("init args = %q, want %q", lines[0], want)
+	}
+}
+
+func TestRunnerError_NotInitialized(t *testing.T) {
+	runner, _ := fakeRunner(t)
+	t.Setenv("FAKE_NOT_INIT", "1")
+
+	_, err := MakeRefreshOnlyPlanJSON(context.Background(), runner, t.TempDir(), PlanOptions{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !errors.Is(err, ErrNotInitialized) || Category(err) != CategoryNotInitialized {
+		t.Fatalf("error not classified as not initialized: %v", err)
+	}
+	if !strings.Contains(err.Error(), "--init") {
+		t.Fatalf("error does not suggest --init: %v", err)
+	}
+}
+
+func TestDecodeUIStream_Diagnostics(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "plan_ui_error.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var diags []Diagnostic
+	if err := decodeUIStream(f, func(m uiMessage) {
+		if m.Diagnostic != nil {
+			diags = append(diags, *m.Diagnostic)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 2 {
+		t.Fatalf("got %d diagnostics, want 2: %+v", len(diags), diags)
+	}
+	errs := ErrorDiagnostics(diags)
+	if len(errs) != 1 || errs[0].Location() != "providers.tf:3" {
+		t.Fatalf("unexpected error diagnostics: %+v", errs)
+	}
+
+	rerr := newRunnerError(RunnerTofu, "plan", errors.New("exit status 1"), "", diags...)
+	want := "tofu plan failed: exit status 1\nError: No valid credential sources found (providers.tf:3)"
+	if rerr.Error() != want {
+		t.Fatalf("Error() = %q, want %q", rerr.Error(), want)
+	}
+	if got := Diagnostics(fmt.Errorf("scan: %w", rerr)); len(got) != 2 {
+		t.Fatalf("Diagnostics() through a wrapped error = %+v", got)
+	}
+}
@@ -0,0 +1,122 @@
+package plan
+
+// MaskSensitive marks every attribute change whose path (or one of its parent
+// paths) matches one of the glob patterns as sensitive, in addition to the
+// before_sensitive/after_sensitive markers already honored by ParseStats. A
+// change whose value is a map or list is also masked when a pattern matches
+// a path nested in it. Renderers never print the values of sensitive attributes.
+func MaskSensitive(s Stats, patterns []string) Stats {
+	if len(patterns) == 0 {
+		return s
+	}
+	mark := func(a AttributeChange) AttributeChange {
+		paths := attributePathPrefixes(a.Path)
+		paths = append(paths, nestedPaths(a.Path, a.Before)...)
+		paths = append(paths, nestedPaths(a.Path, a.After)...)
+		for _, p := range paths {
+			for _, pattern := range patterns {
+				if MatchGlob(pattern, p) {
+					a.Sensitive = true
+					return a
+				}
+			}
+		}
+		return a
+	}
+	s.Changes = mapAttributes(s.Changes, mark)
+	s.External.Changes = mapAttributes(s.External.Changes, mark)
+	s.Resources = mapAttributes(s.Resources, mark)
+	s.Deposed = mapAttributes(s.Deposed, mark)
+	s.DeferredReads = mapAttributes(s.DeferredReads, mark)
+	s.DataChanges = mapAttributes(s.DataChanges, mark)
+	s.Outputs = mapOutputAttributes(s.Outputs, mark)
+	return s
+}
+
+// RevealSensitive clears all sensitive markers so that values are rendered as-is.
+// It backs the --show-sensitive escape hatch and must only be used for interactive output.
+func RevealSensitive(s Stats) Stats {
+	reveal := func(a AttributeChange) AttributeChange {
+		a.Sensitive = false
+		return a
+	}
+	s.Changes = mapAttributes(s.Changes, reveal)
+	s.External.Changes = mapAttributes(s.External.Changes, reveal)
+	s.Resources = mapAttributes(s.Resources, reveal)
+	s.Deposed = mapAttributes(s.Deposed, reveal)
+	s.DeferredReads = mapAttributes(s.DeferredReads, reveal)
+	s.DataChanges = mapAttributes(s.DataChanges, reveal)
+	s.Outputs = mapOutputAttributes(s.Outputs, reveal)
+	return s
+}
+
+// mapAttributes returns a copy of changes with fn applied to every attribute change.
+func mapAttributes(changes []Change, fn func(AttributeChange) AttributeChange) []Change {
+	if changes == nil {
+		return nil
+	}
+	out := make([]Change, len(changes))
+	for i, c := range changes {
+		attrs := make([]AttributeChange, len(c.Attributes))
+		for j, a := range c.Attributes {
+			attrs[j] = fn(a)
+		}
+		c.Attributes = attrs
+		out[i] = c
+	}
+	return out
+}
+
+// mapOutputAttributes is the OutputChange counterpart of mapAttributes.
+func mapOutputAttributes(outputs []OutputChange, fn func(AttributeChange) AttributeChange) []OutputChange {
+	if outputs == nil {
+		return nil
+	}
+	out := make([]OutputChange, len(outputs))
+	for i, o := range outputs {
+		attrs := make([]AttributeChange, len(o.Attributes))
+		for j, a := range o.Attributes {
+			attrs[j] = fn(a)
+		}
+		o.Attributes = attrs
+		out[i] = o
+	}
+	return out
+}
+
+// attributePathPrefixes returns the path and all of its parents, e.g.
+// "a.b[0].c" => ["a", "a.b", "a.b[0]", "a.b[0].c"].
+func attributePathPrefixes(path string) []string {
+	var out []string
+	inQuote := false
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '"' && (i == 0 || path[i-1] != '\\'):
+			inQuote = !inQuote
+		case !inQuote && (c == '.' || c == '[') && i > 0:
+			out = append(out, path[:i])
+		}
+	}
+	return append(out, path)
+}
+
+// nestedPaths returns the attribute paths nested in the map or list v recorded
+// at path, e.g. "cfg" with {"user": "x"} => ["cfg.user"].
+func nestedPaths(path string, v any) []string {
+	var out []string
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			p := joinKey(path, k)
+			out = append(out, p)
+			out = append(out, nestedPaths(p, child)...)
+		}
+	case []any:
+		for i, child := range v {
+			p := joinIndex(path, i)
+			out = append(out, p)
+			out = append(out, nestedPaths(p, child)...)
+		}
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package plan
+
+import "testing"
+
+func TestMaskSensitive_Patterns(t *testing.T) {
+	s := Stats{
+		Changes: []Change{{
+			Address: "aws_db_instance.main",
+			Attributes: []AttributeChange{
+				{Path: "password", Kind: DiffChanged, Before: "a", After: "b"},
+				{Path: "connection.api_token", Kind: DiffChanged, Before: "a", After: "b"},
+				{Path: `tags["team.secret"]`, Kind: DiffAdded, After: "x"},
+				{Path: "instance_class", Kind: DiffChanged, Before: "db.t3.micro", After: "db.t3.large"},
+				{Path: "cfg", Kind: DiffChanged, Before: "legacy", After: map[string]any{"user": "admin"}},
+				{Path: "options", Kind: DiffChanged, Before: "legacy", After: []any{map[string]any{"password": "hunter2"}}},
+			},
+		}},
+	}
+
+	masked := MaskSensitive(s, []string{"password", "connection", "*secret*", "cfg.user", "*.password"})
+	got := map[string]bool{}
+	for _, a := range masked.Changes[0].Attributes {
+		got[a.Path] = a.Sensitive
+	}
+	want := map[string]bool{
+		"password":             true,
+		"connection.api_token": true, // parent path matches
+		`tags["team.secret"]`:  true,
+		"instance_class":       false,
+		"cfg":                  true, // a nested path matches
+		"options":              true,
+	}
+	for path, sensitive := range want {
+		if got[path] != sensitive {
+			t.Fatalf("%s: expected sensitive=%v, got %v", path, sensitive, got[path])
+		}
+	}
+	if s.Changes[0].Attributes[0].Sensitive {
+		t.Fatalf("MaskSensitive must not modify its input")
+	}
+
+	revealed := RevealSensitive(masked)
+	for _, a := range revealed.Changes[0].Attributes {
+		if a.Sensitive {
+			t.Fatalf("expected %s to be revealed", a.Path)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package plan
+
+import "time"
+
+// Suppressed records drift hidden by an accepted exemption.
+type Suppressed struct {
+	Address    string
+	External   bool     // the drift came from resource_drift rather than resource_changes
+	Attributes []string // suppressed attribute paths; empty when the whole resource is suppressed
+	Reason     string
+	Expires    time.Time // zero when the exemption never expires
+}
+
+// Filter rebuilds the planned and external drift of s, passing every drifted
+// change through fn. fn returns the (possibly trimmed) change to keep, or
+// keep=false to drop it entirely; suppressed entries are appended to s.Suppressed.
+func Filter(s Stats, fn func(c Change, external bool) (kept Change, keep bool, suppressed []Suppressed)) Stats {
+	apply := func(changes []Change, external bool) ChangeSummary {
+		var out ChangeSummary
+		for _, c := range changes {
+			kept, keep, sup := fn(c, external)
+			s.Suppressed = append(s.Suppressed, sup...)
+			if keep {
+				out.add(kept)
+			}
+		}
+		return out
+	}
+
+	planned := apply(s.Changes, false)
+	s.External = apply(s.External.Changes, true)
+	s.Updates = planned.Updates
+	s.Deletes = planned.Deletes
+	s.Replaces = planned.Replaces
+	s.CreateBeforeDestroy = planned.CreateBeforeDestroy
+	s.DeleteBeforeCreate = planned.DeleteBeforeCreate
+	s.DriftedResources = planned.Resources
+	s.Changes = planned.Changes
+	return s
+}
@@ -0,0 +1,69 @@
+package plan
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Binary plans may contain secrets, so they are only ever written to private
+// (0700) temporary directories. Live directories are tracked so that they can
+// also be removed when the process is terminated by a signal, where deferred
+// cleanup does not run.
+var tempDirs = struct {
+	sync.Mutex
+	live map[string]struct{}
+}{live: map[string]struct{}{}}
+
+func newTempDir() (string, error) {
+	dir, err := os.MkdirTemp("", "drift-checker-")
+	if err != nil {
+		return "", err
+	}
+	tempDirs.Lock()
+	tempDirs.live[dir] = struct{}{}
+	tempDirs.Unlock()
+	return dir, nil
+}
+
+func removeTempDir(dir string) {
+	_ = os.RemoveAll(dir)
+	tempDirs.Lock()
+	delete(tempDirs.live, dir)
+	tempDirs.Unlock()
+}
+
+// RemoveTempFiles removes every temporary plan directory that is still in use.
+// It is meant to be called right before the process exits on a signal.
+func RemoveTempFiles() {
+	tempDirs.Lock()
+	defer tempDirs.Unlock()
+	for dir := range tempDirs.live {
+		_ = os.RemoveAll(dir)
+		delete(tempDirs.live, dir)
+	}
+}
+
+// copyFile copies src to dst with owner-only permissions, creating the parent
+// directory of dst when needed.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
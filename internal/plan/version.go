@@ -0,0 +1,133 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SupportedFormatVersion is the newest plan JSON format_version this parser
+// understands. Plans with a newer minor version are parsed with a warning;
+// plans with a newer major version are refused.
+const SupportedFormatVersion = "1.2"
+
+// ErrUnsupportedFormat is returned for plan JSON whose format_version has a
+// newer major version than this parser understands.
+var ErrUnsupportedFormat = errors.New("unsupported plan format_version")
+
+// RunnerVersion identifies the runner binary used for a scan.
+type RunnerVersion struct {
+	Kind     RunnerKind
+	Version  string // e.g. "1.8.2"; empty when it could not be detected
+	Platform string // e.g. "linux_amd64"; empty when unknown
+}
+
+// String renders the runner as "tofu 1.8.2", or just the kind when the
+// version is unknown.
+func (v RunnerVersion) String() string {
+	if v.Version == "" {
+		return string(v.Kind)
+	}
+	return string(v.Kind) + " " + v.Version
+}
+
+var (
+	semverRe          = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+	terragruntVersion = regexp.MustCompile(`v?(\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?)`)
+)
+
+// DetectVersion asks the runner for its version: `<runner> version -json` for
+// Terraform and OpenTofu, `terragrunt --version` for Terragrunt. It is run
+// like the plan (see command): in the working directory path, with the
+// environment of opts, and interrupted gracefully when ctx is cancelled.
+func DetectVersion(ctx context.Context, runner RunnerKind, path string, opts PlanOptions) (RunnerVersion, error) {
+	v := RunnerVersion{Kind: runner}
+	args := []string{"version", "-json"}
+	if runner == RunnerTerragrunt {
+		args = []string{"--version"}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := command(ctx, runner, path, args...)
+	cmd.Env = opts.env(cmd)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := run(cmd); err != nil {
+		return v, fmt.Errorf("%s %s failed: %v\n%s", runner, strings.Join(args, " "), err, stderr.String())
+	}
+
+	if runner == RunnerTerragrunt {
+		m := terragruntVersion.FindStringSubmatch(stdout.String())
+		if m == nil {
+			return v, fmt.Errorf("unrecognized terragrunt version output: %q", strings.TrimSpace(stdout.String()))
+		}
+		v.Version = m[1]
+		return v, nil
+	}
+
+	// OpenTofu reports its version under the same key as Terraform.
+	var out struct {
+		Version  string `json:"terraform_version"`
+		Platform string `json:"platform"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return v, fmt.Errorf("invalid %s version output: %w", runner, err)
+	}
+	v.Version, v.Platform = out.Version, out.Platform
+	return v, nil
+}
+
+// checkFormat validates the format_version and terraform_version of a plan.
+// It returns an error wrapping ErrUnsupportedFormat for a newer major format
+// version, and warnings for anything merely suspicious. Older formats (0.x,
+// from Terraform 1.0 and earlier) are parsed as before.
+func checkFormat(formatVersion, terraformVersion string) (warnings []string, err error) {
+	if formatVersion == "" {
+		warnings = append(warnings, "plan JSON has no format_version; assuming "+SupportedFormatVersion)
+	} else {
+		major, minor, ok := parseMajorMinor(formatVersion)
+		supMajor, supMinor, _ := parseMajorMinor(SupportedFormatVersion)
+		switch {
+		case !ok:
+			warnings = append(warnings, fmt.Sprintf("plan format_version %q is not a valid version; assuming %s", formatVersion, SupportedFormatVersion))
+		case major > supMajor:
+			return nil, fmt.Errorf("%w %q (this version of drift-checker understands %d.x up to %s)", ErrUnsupportedFormat, formatVersion, supMajor, SupportedFormatVersion)
+		case major == supMajor && minor > supMinor:
+			warnings = append(warnings, fmt.Sprintf("plan format_version %s is newer than %s; new fields are ignored", formatVersion, SupportedFormatVersion))
+		}
+	}
+
+	if terraformVersion != "" && !semverRe.MatchString(terraformVersion) {
+		warnings = append(warnings, fmt.Sprintf("plan terraform_version %q is not a valid version", terraformVersion))
+	}
+	return warnings, nil
+}
+
+// CheckRunnerVersion warns when the plan was produced by a different version
+// than the detected runner (e.g. a pipeline image switched binaries).
+// Terragrunt wraps another runner, so its version is not comparable.
+func CheckRunnerVersion(s Stats, v RunnerVersion) []string {
+	if v.Version == "" || s.TerraformVersion == "" || v.Kind == RunnerTerragrunt || v.Version == s.TerraformVersion {
+		return nil
+	}
+	return []string{fmt.Sprintf("plan was produced by version %s but %s reports %s", s.TerraformVersion, v.Kind, v.Version)}
+}
+
+// parseMajorMinor parses the first two components of a version such as
+// "1.2" or "1.2.0".
+func parseMajorMinor(v string) (major, minor int, ok bool) {
+	maj, rest, found := strings.Cut(v, ".")
+	if !found {
+		return 0, 0, false
+	}
+	min, _, _ := strings.Cut(rest, ".")
+	var err1, err2 error
+	major, err1 = strconv.Atoi(maj)
+	minor, err2 = strconv.Atoi(min)
+	return major, minor, err1 == nil && err2 == nil
+}
@@ -0,0 +1,62 @@
+package plan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseStats_FormatVersion(t *testing.T) {
+	for _, tc := range []struct {
+		name, json string
+		wantErr    bool
+		wantWarn   string
+	}{
+		{name: "supported", json: `{"format_version":"1.2","terraform_version":"1.8.2"}`},
+		{name: "older", json: `{"format_version":"1.0","terraform_version":"1.5.7"}`},
+		{name: "older major", json: `{"format_version":"0.2","terraform_version":"0.15.5"}`},
+		{name: "three components", json: `{"format_version":"1.2.0","terraform_version":"1.8.2"}`},
+		{name: "newer minor", json: `{"format_version":"1.3","terraform_version":"1.10.0"}`, wantWarn: "newer than 1.2"},
+		{name: "newer major", json: `{"format_version":"2.0"}`, wantErr: true},
+		{name: "newer major, three components", json: `{"format_version":"2.0.1"}`, wantErr: true},
+		{name: "garbage", json: `{"format_version":"x"}`, wantWarn: "not a valid version"},
+		{name: "missing", json: `{}`, wantWarn: "no format_version"},
+		{name: "bad terraform_version", json: `{"format_version":"1.2","terraform_version":"latest"}`, wantWarn: "not a valid version"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := ParseStats([]byte(tc.json))
+			if tc.wantErr {
+				if !errors.Is(err, ErrUnsupportedFormat) {
+					t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseStats error: %v", err)
+			}
+			warnings := strings.Join(s.Warnings, "\n")
+			if tc.wantWarn == "" && warnings != "" {
+				t.Fatalf("unexpected warnings: %s", warnings)
+			}
+			if !strings.Contains(warnings, tc.wantWarn) {
+				t.Fatalf("expected warning %q, got %q", tc.wantWarn, warnings)
+			}
+		})
+	}
+}
+
+func TestCheckRunnerVersion(t *testing.T) {
+	s := Stats{TerraformVersion: "1.5.7"}
+	if w := CheckRunnerVersion(s, RunnerVersion{Kind: RunnerTofu, Version: "1.8.2"}); len(w) != 1 {
+		t.Fatalf("expected a mismatch warning, got %v", w)
+	}
+	if w := CheckRunnerVersion(s, RunnerVersion{Kind: RunnerTerraform, Version: "1.5.7"}); len(w) != 0 {
+		t.Fatalf("unexpected warning: %v", w)
+	}
+	if w := CheckRunnerVersion(s, RunnerVersion{Kind: RunnerTerragrunt, Version: "0.55.1"}); len(w) != 0 {
+		t.Fatalf("terragrunt versions are not comparable: %v", w)
+	}
+	if got := (RunnerVersion{Kind: RunnerTofu, Version: "1.8.2"}).String(); got != "tofu 1.8.2" {
+		t.Fatalf("String() = %q", got)
+	}
+}
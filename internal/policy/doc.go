@@ -0,0 +1,2 @@
+// Package policy evaluates plan resource changes against an ordered, declarative rule file.
+package policy
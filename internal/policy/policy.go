@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+type Outcome string
+
+const (
+	OutcomeAllow Outcome = "allow"
+	OutcomeWarn  Outcome = "warn"
+	OutcomeDeny  Outcome = "deny"
+)
+
+// File is a parsed policy file. Rules are evaluated in order; the first rule
+// matching a resource change decides its outcome. Changes matching no rule are allowed.
+//
+//	rules:
+//	  - type: aws_db_instance
+//	    actions: [delete, replace]
+//	    outcome: deny
+//	    message: databases must never be destroyed
+//	  - module: module.network
+//	    actions: [delete, replace]
+//	    outcome: deny
+//	  - actions: [delete]
+//	    outcome: warn
+type File struct {
+	Path  string `yaml:"-"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule matches resource changes on every selector that is set.
+type Rule struct {
+	Type     string   `yaml:"type"`     // resource type glob
+	Address  string   `yaml:"address"`  // resource address glob
+	Module   string   `yaml:"module"`   // module address glob; also covers instances and nested modules
+	Provider string   `yaml:"provider"` // provider glob, e.g. "aws" or "registry.terraform.io/hashicorp/aws"
+	Actions  []string `yaml:"actions"`  // create|update|delete|replace|read|forget|no-op; empty matches any
+	Reasons  []string `yaml:"reasons"`  // action_reason values, e.g. replace_because_tainted; empty matches any
+	Outcome  Outcome  `yaml:"outcome"`
+	Message  string   `yaml:"message"`
+}
+
+// Verdict is the outcome of evaluating one resource change.
+type Verdict struct {
+	Address string      `json:"address"` // object address; deposed objects include their key
+	Action  plan.Action `json:"action"`
+	Reason  string      `json:"reason,omitempty"` // the change's action_reason, if any
+	Outcome Outcome     `json:"outcome"`
+	Message string      `json:"message,omitempty"`
+	Rule    int         `json:"rule,omitempty"` // 1-based index of the matching rule; 0 when none matched
+}
+
+var validActions = []plan.Action{
+	plan.ActionCreate, plan.ActionUpdate, plan.ActionDelete,
+	plan.ActionReplace, plan.ActionRead, plan.ActionForget, plan.ActionNoOp,
+}
+
+// Load reads and validates a policy file. Unknown keys are errors: a
+// misspelled selector would otherwise widen its rule to every change.
+func Load(p string) (*File, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Path: p}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse %s: %w", p, err)
+	}
+	for i, r := range f.Rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("%s: rule #%d: %w", p, i+1, err)
+		}
+	}
+	return f, nil
+}
+
+func (r Rule) validate() error {
+	switch r.Outcome {
+	case OutcomeAllow, OutcomeWarn, OutcomeDeny:
+	default:
+		return fmt.Errorf("invalid outcome %q (use deny|warn|allow)", r.Outcome)
+	}
+	for _, a := range r.Actions {
+		if !slices.Contains(validActions, plan.Action(a)) {
+			return fmt.Errorf("invalid action %q (use create|update|delete|replace|read|forget|no-op)", a)
+		}
+	}
+	return nil
+}
+
+func (r Rule) matches(c plan.Change) bool {
+	if r.Type != "" && !plan.MatchGlob(r.Type, c.Type) {
+		return false
+	}
+	if r.Address != "" && !plan.MatchGlob(r.Address, c.Address) {
+		return false
+	}
+	if r.Module != "" && !plan.MatchModule(r.Module, c.ModuleAddress) {
+		return false
+	}
+	if r.Provider != "" && !plan.MatchGlob(r.Provider, c.ProviderName) && !plan.MatchGlob(r.Provider, path.Base(c.ProviderName)) {
+		return false
+	}
+	if len(r.Actions) > 0 && !slices.Contains(r.Actions, string(c.Action())) {
+		return false
+	}
+	if len(r.Reasons) > 0 && !slices.Contains(r.Reasons, c.ActionReason) {
+		return false
+	}
+	return true
+}
+
+// Evaluate returns a verdict for every resource change, in plan order.
+// No-op changes are only reported when a rule explicitly matched them.
+func (f *File) Evaluate(changes []plan.Change) []Verdict {
+	out := make([]Verdict, 0, len(changes))
+	for _, c := range changes {
+		v := Verdict{Address: c.ObjectAddress(), Action: c.Action(), Reason: c.ActionReason, Outcome: OutcomeAllow}
+		for i, r := range f.Rules {
+			if r.matches(c) {
+				v.Outcome, v.Message, v.Rule = r.Outcome, r.Message, i+1
+				break
+			}
+		}
+		if v.Action == plan.ActionNoOp && v.Rule == 0 {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// Count returns the number of verdicts with the given outcome.
+func Count(verdicts []Verdict, o Outcome) int {
+	n := 0
+	for _, v := range verdicts {
+		if v.Outcome == o {
+			n++
+		}
+	}
+	return n
+}
+
+// Past is the outcome as a past participle ("denied", "flagged", "allowed").
+func (o Outcome) Past() string {
+	switch o {
+	case OutcomeDeny:
+		return "denied"
+	case OutcomeWarn:
+		return "flagged"
+	}
+	return "allowed"
+}
+
+// Label is the upper-case outcome used in md/text output (e.g. DENY).
+func (o Outcome) Label() string {
+	return strings.ToUpper(string(o))
+}
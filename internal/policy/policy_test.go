@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestEvaluate(t *testing.T) {
+	f, err := Load(filepath.Join("testdata", "policy.yaml"))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	changes := []plan.Change{
+		{Address: "module.db.aws_db_instance.main", ModuleAddress: "module.db", Type: "aws_db_instance", Actions: []string{"create", "delete"}},
+		{Address: "module.network.module.vpc.aws_subnet.a", ModuleAddress: "module.network.module.vpc", Type: "aws_subnet", Actions: []string{"delete"}},
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Actions: []string{"delete"}},
+		{Address: "aws_instance.web", Type: "aws_instance", ProviderName: "registry.terraform.io/hashicorp/aws", Actions: []string{"create"}},
+		{Address: "aws_iam_role.ci", Type: "aws_iam_role", Actions: []string{"update"}},
+		{Address: "aws_iam_role.unchanged", Type: "aws_iam_role", Actions: []string{"no-op"}},
+	}
+
+	verdicts := f.Evaluate(changes)
+	want := []struct {
+		outcome Outcome
+		rule    int
+	}{
+		{OutcomeDeny, 1},
+		{OutcomeDeny, 2},
+		{OutcomeWarn, 3},
+		{OutcomeAllow, 4},
+		{OutcomeAllow, 0},
+	}
+	if len(verdicts) != len(want) {
+		t.Fatalf("expected %d verdicts (no-op skipped), got %d: %+v", len(want), len(verdicts), verdicts)
+	}
+	for i, w := range want {
+		if verdicts[i].Outcome != w.outcome || verdicts[i].Rule != w.rule {
+			t.Fatalf("verdict %d (%s): expected %s by rule %d, got %+v", i, verdicts[i].Address, w.outcome, w.rule, verdicts[i])
+		}
+	}
+	if verdicts[0].Action != plan.ActionReplace {
+		t.Fatalf("expected replace action, got %s", verdicts[0].Action)
+	}
+	if Count(verdicts, OutcomeDeny) != 2 || Count(verdicts, OutcomeWarn) != 1 {
+		t.Fatalf("unexpected counts: %+v", verdicts)
+	}
+}
+
+func TestEvaluate_ModuleInstances(t *testing.T) {
+	f, err := Load(filepath.Join("testdata", "policy.yaml"))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	// The module.network deny rule covers count and for_each instances.
+	changes := []plan.Change{
+		{Address: "module.network[0].aws_vpc.main", ModuleAddress: "module.network[0]", Type: "aws_vpc", Actions: []string{"delete"}},
+		{Address: `module.network["eu"].aws_vpc.main`, ModuleAddress: `module.network["eu"]`, Type: "aws_vpc", Actions: []string{"delete"}},
+		{Address: `module.network["eu"].module.vpc[1].aws_subnet.a`, ModuleAddress: `module.network["eu"].module.vpc[1]`, Type: "aws_subnet", Actions: []string{"delete"}},
+		{Address: "module.networking[0].aws_vpc.main", ModuleAddress: "module.networking[0]", Type: "aws_vpc", Actions: []string{"delete"}},
+	}
+	verdicts := f.Evaluate(changes)
+	for i, want := range []Outcome{OutcomeDeny, OutcomeDeny, OutcomeDeny, OutcomeWarn} {
+		if verdicts[i].Outcome != want {
+			t.Errorf("%s: expected %s, got %+v", verdicts[i].Address, want, verdicts[i])
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"outcome.yaml": "rules:\n  - type: aws_instance\n    outcome: block\n",
+		"action.yaml":  "rules:\n  - actions: [destroy]\n    outcome: deny\n",
+		"typo.yaml":    "rules:\n  - acitons: [delete]\n    outcome: allow\n  - outcome: deny\n",
+	} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(p); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestEvaluate_Reasons(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "policy.yaml")
+	content := "rules:\n" +
+		"  - actions: [replace]\n    reasons: [replace_because_tainted, replace_by_request]\n    outcome: allow\n" +
+		"  - actions: [replace]\n    outcome: deny\n"
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(p)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	verdicts := f.Evaluate([]plan.Change{
+		{Address: "aws_instance.web", Actions: []string{"delete", "create"}, ActionReason: plan.ReasonReplaceTainted},
+		{Address: "aws_db_instance.main", Actions: []string{"delete", "create"}, ActionReason: plan.ReasonReplaceCannotUpdate},
+	})
+	if len(verdicts) != 2 {
+		t.Fatalf("expected 2 verdicts, got %+v", verdicts)
+	}
+	if verdicts[0].Outcome != OutcomeAllow || verdicts[0].Rule != 1 || verdicts[0].Reason != plan.ReasonReplaceTainted {
+		t.Fatalf("tainted replace: unexpected verdict %+v", verdicts[0])
+	}
+	if verdicts[1].Outcome != OutcomeDeny || verdicts[1].Rule != 2 {
+		t.Fatalf("forced replace: unexpected verdict %+v", verdicts[1])
+	}
+}
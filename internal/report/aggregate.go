@@ -0,0 +1,260 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// Metadata describes the scanned estate (cloud, account, region and free-form
+// labels from the config file) and is shown in the aggregated report header.
+type Metadata struct {
+	Cloud   string
+	Account string
+	Region  string
+	Labels  map[string]string
+}
+
+// Section is the outcome of scanning one component of an aggregated report.
+type Section struct {
+	Name    string
+	Path    string
+	Runner  plan.RunnerVersion
+	Options plan.PlanOptions
+	Stats   plan.Stats
+	Err     error // the scan failed; Stats is empty
+}
+
+// Drifted reports whether the component has planned or external drift.
+func (s Section) Drifted() bool {
+	return s.Err == nil && len(plan.DriftedAddresses(s.Stats)) > 0
+}
+
+// Verdict summarizes sections: any drift wins over "no drift", and failed
+// components make the result incomplete.
+func Verdict(sections []Section) string {
+	drifted, failed := countSections(sections)
+	verdict := "no drift"
+	if drifted > 0 {
+		verdict = "drift detected"
+	}
+	if failed > 0 {
+		verdict += fmt.Sprintf(" (incomplete: %d failed)", failed)
+	}
+	return verdict
+}
+
+func countSections(sections []Section) (drifted, failed int) {
+	for _, s := range sections {
+		switch {
+		case s.Err != nil:
+			failed++
+		case s.Drifted():
+			drifted++
+		}
+	}
+	return drifted, failed
+}
+
+// RenderAggregate renders the scans of several components as a single report.
+func RenderAggregate(format string, meta Metadata, sections []Section) (string, error) {
+	switch format {
+	case "", "md", "markdown":
+		return renderAggregateMarkdown(meta, sections), nil
+	case "text", "txt":
+		return renderAggregateText(meta, sections), nil
+	case "json":
+		return renderAggregateJSON(meta, sections)
+	case "sarif":
+		return renderAggregateSARIF(sections)
+	case "junit":
+		suites := make([]JUnitSuite, 0, len(sections))
+		for _, s := range sections {
+			if s.Err != nil {
+				suites = append(suites, JUnitSuite{Name: s.Name, Cases: []JUnitCase{{
+					Name: "scan", ClassName: "error", Failure: "scan failed", Body: s.Err.Error(),
+				}}})
+				continue
+			}
+			ts := ScanSuite(s.Stats, s.Name)
+			ts.Properties = VersionProperties(s.Stats, s.Runner)
+			suites = append(suites, ts)
+		}
+		return BuildJUnit(suites)
+	}
+	return "", ValidateFormat(format)
+}
+
+// renderAggregateSARIF renders the drift of every section as a single SARIF
+// run. Each result records its component, runner and runner version; the
+// run's properties hold the version properties shared by all sections.
+// Failed sections are reported as error notifications.
+func renderAggregateSARIF(sections []Section) (string, error) {
+	var results []SARIFResult
+	var notes []SARIFNotification
+	var props map[string]string
+	for _, s := range sections {
+		if s.Err != nil {
+			notes = append(notes, SARIFNotification{Level: LevelError, Message: fmt.Sprintf("%s (%s) failed to scan%s: %s", s.Name, s.Path, categorySuffix(s.Err), firstLine(s.Err.Error()))})
+			continue
+		}
+		vp := VersionProperties(s.Stats, s.Runner)
+		props = sharedProperties(props, vp)
+		for _, r := range DriftSARIFResults(s.Stats, s.Path) {
+			if r.Properties == nil {
+				r.Properties = map[string]string{}
+			}
+			r.Properties["component"] = s.Name
+			maps.Copy(r.Properties, vp)
+			results = append(results, r)
+		}
+	}
+	return BuildSARIF(results, props, notes...)
+}
+
+// sharedProperties returns the entries of props that next has too, with the
+// same value. A nil props (no section seen yet) yields next.
+func sharedProperties(props, next map[string]string) map[string]string {
+	if props == nil {
+		return maps.Clone(next)
+	}
+	for k, v := range props {
+		if next[k] != v {
+			delete(props, k)
+		}
+	}
+	return props
+}
+
+func metadataLine(meta Metadata, label func(string) string) string {
+	var parts []string
+	for _, kv := range [][2]string{{"Cloud", meta.Cloud}, {"Account", meta.Account}, {"Region", meta.Region}} {
+		if kv[1] != "" {
+			parts = append(parts, label(kv[0])+": "+kv[1])
+		}
+	}
+	for _, k := range sortedKeys(meta.Labels) {
+		parts = append(parts, label(k)+": "+meta.Labels[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func renderAggregateMarkdown(meta Metadata, sections []Section) string {
+	var b strings.Builder
+	drifted, failed := countSections(sections)
+	b.WriteString("# Drift Report\n\n")
+	if line := metadataLine(meta, func(k string) string { return "**" + k + "**" }); line != "" {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+	fmt.Fprintf(&b, "- **Components**: %d (%d drifted, %d failed)\n", len(sections), drifted, failed)
+	fmt.Fprintf(&b, "- **Verdict**: %s\n\n", Verdict(sections))
+
+	for _, s := range sections {
+		if s.Err != nil {
+			fmt.Fprintf(&b, "## %s (`%s`)\n\n%s", s.Name, s.Path, markdownFailure(s.Err))
+			continue
+		}
+		fmt.Fprintf(&b, "## %s (`%s`, %s)\n\n", s.Name, s.Path, s.Runner)
+		b.WriteString(markdownBody(s.Stats))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func renderAggregateText(meta Metadata, sections []Section) string {
+	var b strings.Builder
+	drifted, failed := countSections(sections)
+	b.WriteString("Drift Report\n")
+	if line := metadataLine(meta, func(k string) string { return k }); line != "" {
+		fmt.Fprintf(&b, "%s\n", line)
+	}
+	fmt.Fprintf(&b, "Components: %d (%d drifted, %d failed)\n", len(sections), drifted, failed)
+	fmt.Fprintf(&b, "Verdict: %s\n", Verdict(sections))
+
+	for _, s := range sections {
+		if s.Err != nil {
+			fmt.Fprintf(&b, "\n== %s (%s) ==\n%s", s.Name, s.Path, textFailure(s.Err))
+			continue
+		}
+		fmt.Fprintf(&b, "\n== %s (%s, %s) ==\n", s.Name, s.Path, s.Runner)
+		b.WriteString(textBody(s.Stats))
+	}
+	return b.String()
+}
+
+type jsonComponent struct {
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	Runner        string `json:"runner,omitempty"`
+	RunnerVersion string `json:"runner_version,omitempty"`
+	DriftDetected bool   `json:"drift_detected"`
+	jsonFailure
+	Report *jsonReport `json:"report,omitempty"`
+}
+
+func renderAggregateJSON(meta Metadata, sections []Section) (string, error) {
+	drifted, failed := countSections(sections)
+	payload := struct {
+		Cloud         string            `json:"cloud,omitempty"`
+		Account       string            `json:"account,omitempty"`
+		Region        string            `json:"region,omitempty"`
+		Metadata      map[string]string `json:"metadata,omitempty"`
+		DriftDetected bool              `json:"drift_detected"`
+		Drifted       int               `json:"drifted"`
+		Failed        int               `json:"failed"`
+		Components    []jsonComponent   `json:"components"`
+	}{
+		Cloud:         meta.Cloud,
+		Account:       meta.Account,
+		Region:        meta.Region,
+		Metadata:      meta.Labels,
+		DriftDetected: drifted > 0,
+		Drifted:       drifted,
+		Failed:        failed,
+		Components:    make([]jsonComponent, 0, len(sections)),
+	}
+	for _, s := range sections {
+		jc := jsonComponent{Name: s.Name, Path: s.Path, Runner: string(s.Runner.Kind), RunnerVersion: s.Runner.Version, DriftDetected: s.Drifted()}
+		if s.Err != nil {
+			jc.jsonFailure = newJSONFailure(s.Err)
+		} else {
+			r := newJSONReport(s.Stats)
+			r.Options = newJSONOptions(s.Options)
+			jc.Report = &r
+		}
+		payload.Components = append(payload.Components, jc)
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// categorySuffix labels a scan failure with its runner error category, e.g.
+// " (not-initialized)", or returns "" when the failure is unclassified.
+func categorySuffix(err error) string {
+	if c := plan.Category(err); c != plan.CategoryUnknown {
+		return " (" + string(c) + ")"
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestRenderAggregate(t *testing.T) {
+	drifted := plan.Stats{
+		Updates:          1,
+		DriftedResources: []string{"aws_instance.web"},
+		Changes:          []plan.Change{{Address: "aws_instance.web", Actions: []string{"update"}}},
+	}
+	sections := []Section{
+		{Name: "network", Path: "stacks/network", Runner: plan.RunnerVersion{Kind: plan.RunnerTofu}},
+		{Name: "app", Path: "stacks/app", Runner: plan.RunnerVersion{Kind: plan.RunnerTofu, Version: "1.8.2"}, Stats: drifted},
+		{Name: "dns", Path: "stacks/dns", Err: &plan.RunnerError{
+			Runner: plan.RunnerTofu, Op: "plan", Category: plan.CategoryNotInitialized, Stderr: "Error: Backend initialization required", Err: errors.New("exit status 1"),
+		}},
+	}
+	meta := Metadata{Cloud: "aws", Account: "123456789012", Labels: map[string]string{"team": "platform"}}
+
+	if got := Verdict(sections); got != "drift detected (incomplete: 1 failed)" {
+		t.Fatalf("unexpected verdict: %q", got)
+	}
+
+	md, err := RenderAggregate("md", meta, sections)
+	if err != nil {
+		t.Fatalf("RenderAggregate md: %v", err)
+	}
+	for _, want := range []string{
+		"**Cloud**: aws, **Account**: 123456789012, **team**: platform",
+		"**Components**: 3 (1 drifted, 1 failed)",
+		"## network (`stacks/network`, tofu)",
+		"## app (`stacks/app`, tofu 1.8.2)",
+		"`aws_instance.web`",
+		"**Scan failed** (not-initialized): `tofu plan failed: working directory is not initialized",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "## network") > strings.Index(md, "## app") {
+		t.Errorf("sections not in config order:\n%s", md)
+	}
+
+	out, err := RenderAggregate("json", meta, sections)
+	if err != nil {
+		t.Fatalf("RenderAggregate json: %v", err)
+	}
+	var doc struct {
+		DriftDetected bool `json:"drift_detected"`
+		Failed        int  `json:"failed"`
+		Components    []struct {
+			Name          string          `json:"name"`
+			DriftDetected bool            `json:"drift_detected"`
+			Error         string          `json:"error"`
+			ErrorCategory string          `json:"error_category"`
+			Report        json.RawMessage `json:"report"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if !doc.DriftDetected || doc.Failed != 1 || len(doc.Components) != 3 {
+		t.Fatalf("unexpected summary: %+v", doc)
+	}
+	if !doc.Components[1].DriftDetected || doc.Components[2].Error == "" || doc.Components[2].ErrorCategory != "not-initialized" || doc.Components[2].Report != nil {
+		t.Fatalf("unexpected components: %+v", doc.Components)
+	}
+
+	sarif, err := RenderAggregate("sarif", meta, sections)
+	if err != nil {
+		t.Fatalf("RenderAggregate sarif: %v", err)
+	}
+	var log struct {
+		Runs []struct {
+			Invocations []struct {
+				ExecutionSuccessful        bool `json:"executionSuccessful"`
+				ToolExecutionNotifications []struct {
+					Level   string `json:"level"`
+					Message struct {
+						Text string `json:"text"`
+					} `json:"message"`
+				} `json:"toolExecutionNotifications"`
+			} `json:"invocations"`
+			Results []struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"results"`
+			Properties map[string]string `json:"properties"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(sarif), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v\n%s", err, sarif)
+	}
+	run := log.Runs[0]
+	if run.Properties["runner"] != "tofu" || run.Properties["runner_version"] != "" {
+		t.Fatalf("unexpected run properties (the versions differ): %v", run.Properties)
+	}
+	if len(run.Results) != 1 || run.Results[0].Properties["component"] != "app" || run.Results[0].Properties["runner_version"] != "1.8.2" {
+		t.Fatalf("unexpected results: %+v", run.Results)
+	}
+	if len(run.Invocations) != 1 || run.Invocations[0].ExecutionSuccessful || len(run.Invocations[0].ToolExecutionNotifications) != 1 ||
+		!strings.Contains(run.Invocations[0].ToolExecutionNotifications[0].Message.Text, "dns (stacks/dns) failed to scan (not-initialized)") {
+		t.Fatalf("failed component not reported: %+v", run.Invocations)
+	}
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// hasDataSources reports whether s has data sources worth reporting: deferred
+// reads or changed read results.
+func hasDataSources(s plan.Stats) bool {
+	return len(s.DeferredReads)+len(s.DataChanges) > 0
+}
+
+// MarkdownDataSourceCounts renders the data source summary bullets.
+func MarkdownDataSourceCounts(s plan.Stats) string {
+	out := fmt.Sprintf("- **Data sources**: %d\n- **Deferred reads (read during apply)**: %d\n", s.DataSources, len(s.DeferredReads))
+	if len(s.DataChanges) > 0 {
+		out += fmt.Sprintf("- **Changed data sources**: %d\n", len(s.DataChanges))
+	}
+	return out
+}
+
+// TextDataSourceCounts is the plain-text counterpart of MarkdownDataSourceCounts.
+func TextDataSourceCounts(s plan.Stats) string {
+	out := fmt.Sprintf("Data sources: %d\nDeferred reads (read during apply): %d\n", s.DataSources, len(s.DeferredReads))
+	if len(s.DataChanges) > 0 {
+		out += fmt.Sprintf("Changed data sources: %d\n", len(s.DataChanges))
+	}
+	return out
+}
+
+// markdownDataSources renders the Deferred Reads and Changed Data Sources
+// sections, each only when non-empty.
+func markdownDataSources(s plan.Stats) string {
+	var b strings.Builder
+	if len(s.DeferredReads) > 0 {
+		b.WriteString("### Deferred Reads\n\n")
+		for _, c := range s.DeferredReads {
+			fmt.Fprintf(&b, "- `%s`%s\n", c.Address, markdownReason(c))
+		}
+		b.WriteString("\n")
+	}
+	if len(s.DataChanges) > 0 {
+		b.WriteString("### Changed Data Sources\n\n")
+		b.WriteString(MarkdownChanges(ObjectAddresses(s.DataChanges), s.DataChanges))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// textDataSources is the plain-text counterpart of markdownDataSources.
+func textDataSources(s plan.Stats) string {
+	var b strings.Builder
+	if len(s.DeferredReads) > 0 {
+		b.WriteString("\nDeferred Reads:\n")
+		for _, c := range s.DeferredReads {
+			fmt.Fprintf(&b, "- %s%s\n", c.Address, textReason(c))
+		}
+	}
+	if len(s.DataChanges) > 0 {
+		b.WriteString("\nChanged Data Sources:\n")
+		b.WriteString(TextChanges(ObjectAddresses(s.DataChanges), s.DataChanges))
+	}
+	return b.String()
+}
+
+// JSONDeferredRead is the JSON form of a data source read during apply.
+type JSONDeferredRead struct {
+	Address      string `json:"address"`
+	ActionReason string `json:"action_reason,omitempty"`
+}
+
+func jsonDeferredReads(changes []plan.Change) []JSONDeferredRead {
+	out := make([]JSONDeferredRead, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, JSONDeferredRead{Address: c.Address, ActionReason: c.ActionReason})
+	}
+	return out
+}
+
+// ObjectAddresses returns the object addresses (see plan.Change.ObjectAddress)
+// of changes.
+func ObjectAddresses(changes []plan.Change) []string {
+	out := make([]string, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, c.ObjectAddress())
+	}
+	return out
+}
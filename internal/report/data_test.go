@@ -0,0 +1,36 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestRenderMarkdown_DataSources(t *testing.T) {
+	s := plan.Stats{
+		DataSources:   2,
+		DeferredReads: []plan.Change{{Address: "data.aws_subnet.app", Mode: plan.ModeData, Actions: []string{"read"}, ActionReason: plan.ReasonReadDependencyPending}},
+		DataChanges: []plan.Change{{
+			Address:    "data.aws_ami.ubuntu",
+			Mode:       plan.ModeData,
+			Actions:    []string{"update"},
+			Attributes: []plan.AttributeChange{{Path: "id", Kind: plan.DiffChanged, Before: "ami-111", After: "ami-222"}},
+		}},
+	}
+	md := RenderMarkdown(s, plan.RunnerVersion{Kind: plan.RunnerTofu})
+	for _, want := range []string{
+		"- **Data sources**: 2\n- **Deferred reads (read during apply)**: 1\n- **Changed data sources**: 1\n",
+		"### Deferred Reads\n\n- `data.aws_subnet.app`: read during apply because a dependency has pending changes\n",
+		"### Changed Data Sources\n\n- `data.aws_ami.ubuntu`\n  - `id`: `\"ami-111\"` → `\"ami-222\"`\n",
+		"_No drift detected._",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	if text := RenderText(plan.Stats{}, plan.RunnerVersion{Kind: plan.RunnerTofu}); strings.Contains(text, "Data sources") {
+		t.Errorf("data source counts shown without data sources:\n%s", text)
+	}
+}
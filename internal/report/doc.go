@@ -1,2 +1,2 @@
 // Package report provides formatting of scan results in markdown and text.
-package report
\ No newline at end of file
+package report
@@ -0,0 +1,120 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// RenderFailure renders a scan that failed before producing a plan, so the
+// runner's diagnostics end up in the report (e.g. a PR comment) rather than
+// only in the logs. SARIF reports carry the failure as error notifications of
+// a run without results, and JUnit reports as a failing "scan" case of a suite
+// named root, like failed components of an aggregated report.
+func RenderFailure(format string, err error, root string) (string, error) {
+	heading := "Drift Summary"
+	var runner plan.RunnerVersion
+	var re *plan.RunnerError
+	if errors.As(err, &re) {
+		heading += fmt.Sprintf(" (%s)", re.Runner)
+		runner.Kind = re.Runner
+	}
+
+	switch format {
+	case "", "md", "markdown":
+		return fmt.Sprintf("## %s\n\n%s", heading, markdownFailure(err)), nil
+	case "text", "txt":
+		return heading + "\n" + textFailure(err), nil
+	case "json":
+		b, jerr := json.Marshal(struct {
+			DriftDetected bool `json:"drift_detected"`
+			jsonFailure
+		}{jsonFailure: newJSONFailure(err)})
+		if jerr != nil {
+			return "", jerr
+		}
+		return string(b), nil
+	case "sarif":
+		notes := []SARIFNotification{{Level: LevelError, Message: fmt.Sprintf("Scan failed%s: %s", categorySuffix(err), firstLine(err.Error()))}}
+		for _, d := range plan.ErrorDiagnostics(plan.Diagnostics(err)) {
+			notes = append(notes, SARIFNotification{Level: LevelError, Message: d.String()})
+		}
+		return BuildSARIF(nil, VersionProperties(plan.Stats{}, runner), notes...)
+	case "junit":
+		return BuildJUnit([]JUnitSuite{{Name: root, Properties: VersionProperties(plan.Stats{}, runner), Cases: []JUnitCase{{
+			Name: "scan", ClassName: "error", Failure: "scan failed" + categorySuffix(err), Body: textFailure(err),
+		}}}})
+	}
+	return "", ValidateFormat(format)
+}
+
+// jsonFailure describes a failed scan in JSON reports.
+type jsonFailure struct {
+	Error         string            `json:"error,omitempty"`
+	ErrorCategory string            `json:"error_category,omitempty"`
+	Diagnostics   []plan.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+func newJSONFailure(err error) jsonFailure {
+	if err == nil {
+		return jsonFailure{}
+	}
+	return jsonFailure{
+		Error:         err.Error(),
+		ErrorCategory: string(plan.Category(err)),
+		Diagnostics:   plan.Diagnostics(err),
+	}
+}
+
+// markdownFailure renders the failure line followed by the error diagnostics,
+// each with its file:line and detail.
+func markdownFailure(err error) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Scan failed**%s: `%s`\n\n", categorySuffix(err), firstLine(err.Error()))
+	diags := plan.ErrorDiagnostics(plan.Diagnostics(err))
+	for _, d := range diags {
+		fmt.Fprintf(&b, "- **Error**: %s", d.Summary)
+		if loc := d.Location(); loc != "" {
+			fmt.Fprintf(&b, " (`%s`)", loc)
+		}
+		b.WriteString("\n")
+		for _, line := range detailLines(d.Detail) {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	if len(diags) > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// textFailure renders the failure like markdownFailure; without diagnostics
+// the whole error (including the runner's stderr) is printed.
+func textFailure(err error) string {
+	diags := plan.ErrorDiagnostics(plan.Diagnostics(err))
+	if len(diags) == 0 {
+		return fmt.Sprintf("Scan failed%s: %s\n", categorySuffix(err), err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Scan failed%s: %s\n", categorySuffix(err), firstLine(err.Error()))
+	for _, d := range diags {
+		fmt.Fprintf(&b, "  %s\n", d)
+		for _, line := range detailLines(d.Detail) {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+func detailLines(detail string) []string {
+	var lines []string
+	for _, l := range strings.Split(strings.TrimSpace(detail), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,102 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestRenderFailure(t *testing.T) {
+	err := fmt.Errorf("component app: %w", &plan.RunnerError{
+		Runner: plan.RunnerTofu,
+		Op:     "plan",
+		Err:    errors.New("exit status 1"),
+		Diagnostics: []plan.Diagnostic{
+			{Severity: "warning", Summary: "Deprecated attribute"},
+			{
+				Severity: "error",
+				Summary:  "No valid credential sources found",
+				Detail:   "Please see the provider docs\nfor credentials.",
+				Range:    &plan.DiagnosticRange{Filename: "providers.tf", Start: plan.DiagnosticPos{Line: 3, Column: 1}},
+			},
+		},
+	})
+
+	md, rerr := RenderFailure("md", err, "infra")
+	if rerr != nil {
+		t.Fatal(rerr)
+	}
+	for _, want := range []string{
+		"## Drift Summary (tofu)",
+		"**Scan failed**: `component app: tofu plan failed: exit status 1`",
+		"- **Error**: No valid credential sources found (`providers.tf:3`)\n  Please see the provider docs\n  for credentials.\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Deprecated attribute") {
+		t.Errorf("warning diagnostic rendered as a failure:\n%s", md)
+	}
+
+	text, _ := RenderFailure("text", err, "infra")
+	if !strings.Contains(text, "  Error: No valid credential sources found (providers.tf:3)\n    Please see the provider docs\n") {
+		t.Errorf("unexpected text report:\n%s", text)
+	}
+
+	out, _ := RenderFailure("json", err, "infra")
+	var doc struct {
+		DriftDetected bool              `json:"drift_detected"`
+		Error         string            `json:"error"`
+		Diagnostics   []plan.Diagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if doc.DriftDetected || doc.Error == "" || len(doc.Diagnostics) != 2 || doc.Diagnostics[1].Location() != "providers.tf:3" {
+		t.Fatalf("unexpected JSON report: %s", out)
+	}
+
+	sarif, _ := RenderFailure("sarif", err, "infra")
+	var log struct {
+		Runs []struct {
+			Results     []any `json:"results"`
+			Invocations []struct {
+				ExecutionSuccessful        bool `json:"executionSuccessful"`
+				ToolExecutionNotifications []struct {
+					Level   string `json:"level"`
+					Message struct {
+						Text string `json:"text"`
+					} `json:"message"`
+				} `json:"toolExecutionNotifications"`
+			} `json:"invocations"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(sarif), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v\n%s", err, sarif)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 0 || len(log.Runs[0].Invocations) != 1 {
+		t.Fatalf("unexpected SARIF report: %s", sarif)
+	}
+	inv := log.Runs[0].Invocations[0]
+	if inv.ExecutionSuccessful || len(inv.ToolExecutionNotifications) != 2 ||
+		inv.ToolExecutionNotifications[1].Message.Text != "Error: No valid credential sources found (providers.tf:3)" {
+		t.Errorf("unexpected SARIF invocation: %s", sarif)
+	}
+
+	junit, _ := RenderFailure("junit", err, "infra")
+	for _, want := range []string{
+		`<testsuite name="infra" tests="1" failures="1"`,
+		`<testcase name="scan" classname="error">`,
+		`<failure message="scan failed" type="error">`,
+		"No valid credential sources found (providers.tf:3)",
+	} {
+		if !strings.Contains(junit, want) {
+			t.Errorf("JUnit report missing %q:\n%s", want, junit)
+		}
+	}
+}
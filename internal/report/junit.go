@@ -0,0 +1,151 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// JUnitSuite is one component/workspace in a JUnit report.
+type JUnitSuite struct {
+	Name       string
+	Properties map[string]string // e.g. runner and plan versions
+	Cases      []JUnitCase
+}
+
+// JUnitCase is one resource. A non-empty Failure fails the case; a non-empty
+// Skipped marks it as skipped (e.g. suppressed drift).
+type JUnitCase struct {
+	Name      string
+	ClassName string
+	Failure   string // short failure message
+	Body      string // failure details (action and attribute diff)
+	Skipped   string
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// RenderJUnit renders the scan as a JUnit XML report with a single test suite:
+// every resource in the plan is a test case that fails when it drifted, and
+// suppressed drift is reported as skipped. The runner and plan versions are
+// recorded as suite properties.
+func RenderJUnit(s plan.Stats, suite string, runner plan.RunnerVersion) (string, error) {
+	ts := ScanSuite(s, suite)
+	ts.Properties = VersionProperties(s, runner)
+	return BuildJUnit([]JUnitSuite{ts})
+}
+
+// ScanSuite builds the JUnit test suite of one scanned component.
+func ScanSuite(s plan.Stats, name string) JUnitSuite {
+	suite := JUnitSuite{Name: name}
+	suppressed := map[string]plan.Suppressed{}
+	for _, sup := range s.Suppressed {
+		if len(sup.Attributes) == 0 {
+			suppressed[sup.Address] = sup
+		}
+	}
+
+	for _, r := range s.Resources {
+		tc := JUnitCase{Name: r.ObjectAddress(), ClassName: "drift"}
+		if c, ok := s.ChangeFor(r.ObjectAddress()); ok {
+			tc.Failure = fmt.Sprintf("drifted (%s)", c.Action())
+			tc.Body = ChangeDetails(c, s.Changes)
+		} else if sup, ok := suppressed[r.Address]; ok && !sup.External {
+			tc.Skipped = "suppressed: " + sup.Reason
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for _, c := range s.External.Changes {
+		suite.Cases = append(suite.Cases, JUnitCase{
+			Name:      c.ObjectAddress(),
+			ClassName: "external-drift",
+			Failure:   fmt.Sprintf("changed outside of IaC (%s)", c.Action()),
+			Body:      ChangeDetails(c, s.External.Changes),
+		})
+	}
+	for _, sup := range s.Suppressed {
+		if sup.External && len(sup.Attributes) == 0 {
+			suite.Cases = append(suite.Cases, JUnitCase{Name: sup.Address, ClassName: "external-drift", Skipped: "suppressed: " + sup.Reason})
+		}
+	}
+	return suite
+}
+
+// ChangeDetails is the plain-text failure body of a change: its actions and
+// attribute diff, with sensitive values masked.
+func ChangeDetails(c plan.Change, changes []plan.Change) string {
+	return fmt.Sprintf("actions: %v\n%s", c.Actions, TextChanges([]string{c.ObjectAddress()}, changes))
+}
+
+// BuildJUnit marshals suites into a JUnit XML document.
+func BuildJUnit(suites []JUnitSuite) (string, error) {
+	doc := junitTestSuites{Name: toolName}
+	for _, s := range suites {
+		ts := junitTestSuite{Name: s.Name, Tests: len(s.Cases), Cases: make([]junitTestCase, 0, len(s.Cases))}
+		for _, k := range sortedKeys(s.Properties) {
+			ts.Properties = append(ts.Properties, junitProperty{Name: k, Value: s.Properties[k]})
+		}
+		for _, c := range s.Cases {
+			tc := junitTestCase{Name: c.Name, ClassName: c.ClassName}
+			switch {
+			case c.Failure != "":
+				tc.Failure = &junitFailure{Message: c.Failure, Type: c.ClassName, Body: c.Body}
+				ts.Failures++
+			case c.Skipped != "":
+				tc.Skipped = &junitSkipped{Message: c.Skipped}
+				ts.Skipped++
+			}
+			ts.Cases = append(ts.Cases, tc)
+		}
+		doc.Tests += ts.Tests
+		doc.Failures += ts.Failures
+		doc.Skipped += ts.Skipped
+		doc.Suites = append(doc.Suites, ts)
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(b), nil
+}
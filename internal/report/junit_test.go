@@ -0,0 +1,64 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestRenderJUnit(t *testing.T) {
+	web := plan.Change{
+		Address: "aws_instance.web", Actions: []string{"update"},
+		Attributes: []plan.AttributeChange{{Path: "instance_type", Kind: plan.DiffChanged, Before: "t3.micro", After: "t3.large"}},
+	}
+	s := plan.Stats{
+		Updates:          1,
+		DriftedResources: []string{"aws_instance.web"},
+		Changes:          []plan.Change{web},
+		Resources: []plan.Change{
+			web,
+			{Address: "aws_s3_bucket.logs", Actions: []string{"no-op"}},
+			{Address: "aws_autoscaling_group.app", Actions: []string{"update"}},
+		},
+		Suppressed: []plan.Suppressed{{Address: "aws_autoscaling_group.app", Reason: "managed by autoscaling"}},
+	}
+
+	out, err := RenderJUnit(s, "infra/prod", plan.RunnerVersion{})
+	if err != nil {
+		t.Fatalf("RenderJUnit error: %v", err)
+	}
+	if !strings.HasPrefix(out, "<?xml") {
+		t.Fatalf("missing XML header:\n%s", out)
+	}
+
+	var doc struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Skipped  int `xml:"skipped,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+					Body    string `xml:",chardata"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\n%s", err, out)
+	}
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Skipped != 1 {
+		t.Fatalf("unexpected totals: tests=%d failures=%d skipped=%d", doc.Tests, doc.Failures, doc.Skipped)
+	}
+	if len(doc.Suites) != 1 || doc.Suites[0].Name != "infra/prod" {
+		t.Fatalf("unexpected suites: %+v", doc.Suites)
+	}
+	f := doc.Suites[0].Cases[0].Failure
+	if f == nil || f.Message != "drifted (update)" || !strings.Contains(f.Body, `instance_type: "t3.micro" -> "t3.large"`) {
+		t.Fatalf("unexpected failure for aws_instance.web: %+v", f)
+	}
+}
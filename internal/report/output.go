@@ -0,0 +1,66 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// MarkdownOutputs renders a bullet per changed output. A scalar value is shown
+// on the bullet itself; structured values list their changed paths below it.
+// Sensitive values are masked.
+func MarkdownOutputs(outputs []plan.OutputChange) string {
+	var b strings.Builder
+	for _, o := range outputs {
+		if a, ok := scalarOutput(o); ok {
+			fmt.Fprintf(&b, "- `%s` (%s): `%s` → `%s`\n", o.Name, o.Action(), beforeText(a), afterText(a))
+			continue
+		}
+		fmt.Fprintf(&b, "- `%s` (%s)\n", o.Name, o.Action())
+		for _, a := range o.Attributes {
+			fmt.Fprintf(&b, "  - `%s`: `%s` → `%s`\n", a.Path, beforeText(a), afterText(a))
+		}
+	}
+	return b.String()
+}
+
+// TextOutputs is the plain-text counterpart of MarkdownOutputs.
+func TextOutputs(outputs []plan.OutputChange) string {
+	var b strings.Builder
+	for _, o := range outputs {
+		if a, ok := scalarOutput(o); ok {
+			fmt.Fprintf(&b, "- %s (%s): %s -> %s\n", o.Name, o.Action(), beforeText(a), afterText(a))
+			continue
+		}
+		fmt.Fprintf(&b, "- %s (%s)\n", o.Name, o.Action())
+		for _, a := range o.Attributes {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", a.Path, beforeText(a), afterText(a))
+		}
+	}
+	return b.String()
+}
+
+// scalarOutput returns the single value change of an output whose whole value
+// changed (rather than parts of a map or list).
+func scalarOutput(o plan.OutputChange) (plan.AttributeChange, bool) {
+	if len(o.Attributes) == 1 && o.Attributes[0].Path == o.Name {
+		return o.Attributes[0], true
+	}
+	return plan.AttributeChange{}, false
+}
+
+// JSONOutput is the JSON form of a plan.OutputChange.
+type JSONOutput struct {
+	Name       string          `json:"name"`
+	Actions    []string        `json:"actions"`
+	Attributes []JSONAttribute `json:"attributes"`
+}
+
+func JSONOutputs(outputs []plan.OutputChange) []JSONOutput {
+	out := make([]JSONOutput, 0, len(outputs))
+	for _, o := range outputs {
+		out = append(out, JSONOutput{Name: o.Name, Actions: o.Actions, Attributes: jsonAttributes(o.Attributes)})
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestRenderOutputs(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("..", "plan", "testdata", "plan_outputs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := plan.ParseStats(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := RenderMarkdown(s, plan.RunnerVersion{Kind: plan.RunnerTofu})
+	for _, want := range []string{
+		"- **Changed outputs**: 3\n",
+		"### Changed Outputs\n\n",
+		"- `db_password` (update): `(sensitive)` → `(sensitive)`\n",
+		"- `endpoint` (update): `\"10.0.0.10\"` → `\"10.0.0.42\"`\n",
+		"- `network` (update)\n  - `network.subnets[1]`: `\"subnet-b\"` → `\"subnet-c\"`\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "hunter2") || strings.Contains(md, "correct-horse") {
+		t.Fatalf("markdown leaks a sensitive output:\n%s", md)
+	}
+
+	js, err := RenderJSON(s, plan.RunnerVersion{}, plan.PlanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(js, "hunter2") {
+		t.Fatalf("json leaks a sensitive output: %s", js)
+	}
+	var r struct {
+		Outputs []JSONOutput `json:"outputs"`
+	}
+	if err := json.Unmarshal([]byte(js), &r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Outputs) != 3 || r.Outputs[1].Name != "endpoint" || r.Outputs[1].Attributes[0].After != "10.0.0.42" {
+		t.Fatalf("unexpected outputs: %+v", r.Outputs)
+	}
+}
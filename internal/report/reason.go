@@ -0,0 +1,78 @@
+package report
+
+import (
+	"strings"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// reasonSummary explains why c is replaced or deleted (its action_reason),
+// e.g. "replaced because attribute `engine_version` forces new resource", or
+// returns "" when the plan gives no reason. Deposed objects, which carry no
+// action_reason, are explained as such. code formats attribute paths.
+func reasonSummary(c plan.Change, code func(string) string) string {
+	switch c.ActionReason {
+	case "":
+		if c.IsDeposed() {
+			return "deposed object left behind by an incomplete create-before-destroy replace"
+		}
+		return ""
+	case plan.ReasonReplaceCannotUpdate:
+		paths := make([]string, 0, len(c.ReplacePaths))
+		for _, p := range c.ReplacePaths {
+			paths = append(paths, code(p))
+		}
+		switch len(paths) {
+		case 0:
+			return "replaced because it cannot be updated in place"
+		case 1:
+			return "replaced because attribute " + paths[0] + " forces new resource"
+		}
+		return "replaced because attributes " + strings.Join(paths, ", ") + " force new resource"
+	case plan.ReasonReplaceTainted:
+		return "replaced because it is tainted"
+	case plan.ReasonReplaceByRequest:
+		return "replaced by request (-replace)"
+	case plan.ReasonReplaceByTriggers:
+		return "replaced because of replace_triggered_by"
+	case plan.ReasonDeleteNoConfig:
+		return "deleted because it is no longer in the configuration"
+	case plan.ReasonDeleteNoModule:
+		return "deleted because its module is no longer in the configuration"
+	case plan.ReasonDeleteRepetition:
+		return "deleted because its instance key no longer matches count/for_each"
+	case plan.ReasonDeleteCountIndex:
+		return "deleted because its count index is out of range"
+	case plan.ReasonDeleteEachKey:
+		return "deleted because its for_each key no longer exists"
+	case plan.ReasonDeleteNoMoveTarget:
+		return "deleted because its moved target does not exist"
+	case plan.ReasonReadConfigUnknown:
+		return "read during apply because its configuration depends on unknown values"
+	case plan.ReasonReadDependencyPending:
+		return "read during apply because a dependency has pending changes"
+	case plan.ReasonReadCheckNested:
+		return "read during apply as part of a check block"
+	}
+	return strings.ReplaceAll(c.ActionReason, "_", " ")
+}
+
+// ReasonText is the plain-text form of the reason c is replaced or deleted,
+// or "" when the plan gives none.
+func ReasonText(c plan.Change) string {
+	return reasonSummary(c, func(s string) string { return s })
+}
+
+func markdownReason(c plan.Change) string {
+	if r := reasonSummary(c, func(s string) string { return "`" + s + "`" }); r != "" {
+		return ": " + r
+	}
+	return ""
+}
+
+func textReason(c plan.Change) string {
+	if r := ReasonText(c); r != "" {
+		return ": " + r
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestMarkdownChanges_Reasons(t *testing.T) {
+	changes := []plan.Change{
+		{Address: "aws_db_instance.main", Actions: []string{"delete", "create"}, ActionReason: plan.ReasonReplaceCannotUpdate, ReplacePaths: []string{"engine_version"}},
+		{Address: "aws_security_group.app", Actions: []string{"delete", "create"}, ActionReason: plan.ReasonReplaceCannotUpdate, ReplacePaths: []string{"ingress[0].cidr_blocks", "name"}},
+		{Address: "aws_instance.web", Actions: []string{"delete", "create"}, ActionReason: plan.ReasonReplaceTainted},
+		{Address: "aws_s3_bucket.old", Actions: []string{"delete"}, ActionReason: plan.ReasonDeleteNoConfig},
+		{Address: "aws_instance.api", Actions: []string{"update"}},
+	}
+	addrs := make([]string, 0, len(changes))
+	for _, c := range changes {
+		addrs = append(addrs, c.Address)
+	}
+
+	md := MarkdownChanges(addrs, changes)
+	for _, want := range []string{
+		"- `aws_db_instance.main`: replaced because attribute `engine_version` forces new resource\n",
+		"- `aws_security_group.app`: replaced because attributes `ingress[0].cidr_blocks`, `name` force new resource\n",
+		"- `aws_instance.web`: replaced because it is tainted\n",
+		"- `aws_s3_bucket.old`: deleted because it is no longer in the configuration\n",
+		"- `aws_instance.api`\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	text := TextChanges(addrs[:1], changes)
+	if want := "- aws_db_instance.main: replaced because attribute engine_version forces new resource\n"; text != want {
+		t.Errorf("TextChanges() = %q, want %q", text, want)
+	}
+}
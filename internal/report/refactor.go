@@ -0,0 +1,97 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// Refactors returns the moved, imported and forgotten resources of changes, in
+// plan order.
+func Refactors(changes []plan.Change) []plan.Change {
+	var out []plan.Change
+	for _, c := range changes {
+		if c.Moved() || c.Imported() || c.Action() == plan.ActionForget {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// refactorSummary describes how c is refactored, e.g. "moved from `a.b`;
+// imported (id `i-123`)". code formats addresses and ids.
+func refactorSummary(c plan.Change, code func(string) string) string {
+	var parts []string
+	if c.Moved() {
+		parts = append(parts, "moved from "+code(c.PreviousAddress))
+	}
+	if c.Imported() {
+		if c.Importing.ID != "" {
+			parts = append(parts, fmt.Sprintf("imported (id %s)", code(c.Importing.ID)))
+		} else {
+			parts = append(parts, "imported")
+		}
+	}
+	if c.Action() == plan.ActionForget {
+		parts = append(parts, "removed from state (not destroyed)")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// hasRefactors reports whether s contains moved, imported or forgotten resources.
+func hasRefactors(s plan.Stats) bool {
+	return s.Moves+s.Imports+s.Forgets > 0
+}
+
+// MarkdownRefactorCounts renders the Moved/Imported/Forgotten summary bullets.
+func MarkdownRefactorCounts(moves, imports, forgets int) string {
+	return fmt.Sprintf("- **Moved**: %d\n- **Imported**: %d\n- **Forgotten (removed from state)**: %d\n", moves, imports, forgets)
+}
+
+// TextRefactorCounts is the plain-text counterpart of MarkdownRefactorCounts.
+func TextRefactorCounts(moves, imports, forgets int) string {
+	return fmt.Sprintf("Moved: %d\nImported: %d\nForgotten (removed from state): %d\n", moves, imports, forgets)
+}
+
+// MarkdownRefactors renders a bullet per refactored resource.
+func MarkdownRefactors(changes []plan.Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		fmt.Fprintf(&b, "- `%s`: %s\n", c.Address, refactorSummary(c, func(s string) string { return "`" + s + "`" }))
+	}
+	return b.String()
+}
+
+// TextRefactors is the plain-text counterpart of MarkdownRefactors.
+func TextRefactors(changes []plan.Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		fmt.Fprintf(&b, "- %s: %s\n", c.Address, refactorSummary(c, func(s string) string { return s }))
+	}
+	return b.String()
+}
+
+// JSONRefactor is the JSON form of a moved, imported or forgotten resource.
+type JSONRefactor struct {
+	Address         string `json:"address"`
+	PreviousAddress string `json:"previous_address,omitempty"`
+	Imported        bool   `json:"imported,omitempty"`
+	ImportID        string `json:"import_id,omitempty"`
+	Forgotten       bool   `json:"forgotten,omitempty"`
+}
+
+func JSONRefactors(changes []plan.Change) []JSONRefactor {
+	out := make([]JSONRefactor, 0, len(changes))
+	for _, c := range changes {
+		jr := JSONRefactor{Address: c.Address, Imported: c.Imported(), Forgotten: c.Action() == plan.ActionForget}
+		if c.Moved() {
+			jr.PreviousAddress = c.PreviousAddress
+		}
+		if c.Importing != nil {
+			jr.ImportID = c.Importing.ID
+		}
+		out = append(out, jr)
+	}
+	return out
+}
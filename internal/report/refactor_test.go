@@ -0,0 +1,48 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestRenderRefactors(t *testing.T) {
+	s := plan.Stats{
+		Moves:   1,
+		Imports: 1,
+		Forgets: 1,
+		Resources: []plan.Change{
+			{Address: "aws_s3_bucket.logs", PreviousAddress: "aws_s3_bucket.log_bucket", Actions: []string{"no-op"}},
+			{Address: "aws_iam_role.ci", Importing: &plan.Import{ID: "ci"}, Actions: []string{"no-op"}},
+			{Address: "aws_instance.legacy", Actions: []string{"forget"}},
+			{Address: "aws_instance.web", Actions: []string{"no-op"}},
+		},
+	}
+
+	md := RenderMarkdown(s, plan.RunnerVersion{Kind: plan.RunnerTofu})
+	for _, want := range []string{
+		"- **Moved**: 1\n- **Imported**: 1\n- **Forgotten (removed from state)**: 1\n",
+		"- `aws_s3_bucket.logs`: moved from `aws_s3_bucket.log_bucket`\n",
+		"- `aws_iam_role.ci`: imported (id `ci`)\n",
+		"- `aws_instance.legacy`: removed from state (not destroyed)\n",
+		"_No drift detected._",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "aws_instance.web") {
+		t.Errorf("unchanged resource listed as refactored:\n%s", md)
+	}
+
+	text := RenderText(plan.Stats{Resources: s.Resources[:1], Moves: 1}, plan.RunnerVersion{})
+	if !strings.Contains(text, "Moved: 1\n") || !strings.Contains(text, "- aws_s3_bucket.logs: moved from aws_s3_bucket.log_bucket\n") {
+		t.Errorf("unexpected text report:\n%s", text)
+	}
+
+	// Plans without refactors keep the short summary.
+	if md := RenderMarkdown(plan.Stats{}, plan.RunnerVersion{}); strings.Contains(md, "Moved") {
+		t.Errorf("refactor counts rendered without refactors:\n%s", md)
+	}
+}
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+// ValidateFormat reports an error for output formats no renderer supports.
+func ValidateFormat(format string) error {
+	switch format {
+	case "", "md", "markdown", "text", "txt", "json", "sarif", "junit":
+		return nil
+	}
+	return fmt.Errorf("unsupported format %q (use md|text|json|sarif|junit)", format)
+}
+
+// Render renders a single scan in the requested format. root is the scanned
+// working directory (used for SARIF locations and as the JUnit suite name);
+// opts are the runner options of the plan, recorded in the JSON report.
+func Render(format string, s plan.Stats, runner plan.RunnerVersion, opts plan.PlanOptions, root string) (string, error) {
+	switch format {
+	case "", "md", "markdown":
+		return RenderMarkdown(s, runner), nil
+	case "text", "txt":
+		return RenderText(s, runner), nil
+	case "json":
+		return RenderJSON(s, runner, opts)
+	case "sarif":
+		return RenderSARIF(s, root, runner)
+	case "junit":
+		return RenderJUnit(s, root, runner)
+	}
+	return "", ValidateFormat(format)
+}
@@ -8,66 +8,416 @@ import (
 	"github.com/ha36d/drift-checker/internal/plan"
 )
 
-func RenderMarkdown(s plan.Stats, runner plan.RunnerKind) string {
+const (
+	sensitivePlaceholder = "(sensitive)"
+	unknownPlaceholder   = "(known after apply)"
+)
+
+// RenderMarkdown renders a single scan; the heading names the runner and its version.
+func RenderMarkdown(s plan.Stats, runner plan.RunnerVersion) string {
+	return fmt.Sprintf("## Drift Summary (%s)\n\n", runner) + markdownBody(s)
+}
+
+// markdownBody renders everything below the summary heading, so aggregated
+// reports can reuse it under a per-component heading.
+func markdownBody(s plan.Stats) string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "## Drift Summary (%s)\n\n", runner)
+	if v := PlanVersion(s); v != "" {
+		fmt.Fprintf(&b, "- **Plan format**: %s\n", v)
+	}
 	fmt.Fprintf(&b, "- **Updates**: %d\n", s.Updates)
 	fmt.Fprintf(&b, "- **Replaces**: %d\n", s.Replaces)
+	if s.Replaces > 0 {
+		fmt.Fprintf(&b, "  - **Create before destroy**: %d\n", s.CreateBeforeDestroy)
+		fmt.Fprintf(&b, "  - **Delete before create**: %d\n", s.DeleteBeforeCreate)
+	}
 	fmt.Fprintf(&b, "- **Deletes**: %d\n", s.Deletes)
-	fmt.Fprintf(&b, "- **Total changed resources**: %d\n\n", len(s.DriftedResources))
+	fmt.Fprintf(&b, "- **Total changed resources**: %d\n", len(s.DriftedResources))
+	fmt.Fprintf(&b, "- **External drift (changed outside IaC)**: %d\n", len(s.External.Resources))
+	if hasRefactors(s) {
+		b.WriteString(MarkdownRefactorCounts(s.Moves, s.Imports, s.Forgets))
+	}
+	if hasDataSources(s) {
+		b.WriteString(MarkdownDataSourceCounts(s))
+	}
+	if len(s.Outputs) > 0 {
+		fmt.Fprintf(&b, "- **Changed outputs**: %d\n", len(s.Outputs))
+	}
+	b.WriteString("\n")
 
 	if len(s.DriftedResources) > 0 {
 		b.WriteString("### Drifted Resources\n\n")
-		for _, addr := range s.DriftedResources {
-			fmt.Fprintf(&b, "- `%s`\n", addr)
+		b.WriteString(MarkdownChanges(s.DriftedResources, s.Changes))
+		b.WriteString("\n")
+	}
+	if len(s.External.Resources) > 0 {
+		b.WriteString("### External Drift\n\n")
+		b.WriteString(MarkdownChanges(s.External.Resources, s.External.Changes))
+		b.WriteString("\n")
+	}
+	if refactors := Refactors(s.Resources); len(refactors) > 0 {
+		b.WriteString("### Moved, Imported and Forgotten Resources\n\n")
+		b.WriteString(MarkdownRefactors(refactors))
+		b.WriteString("\n")
+	}
+	b.WriteString(markdownDataSources(s))
+	if len(s.Outputs) > 0 {
+		b.WriteString("### Changed Outputs\n\n")
+		b.WriteString(MarkdownOutputs(s.Outputs))
+		b.WriteString("\n")
+	}
+	if len(s.Suppressed) > 0 {
+		b.WriteString("### Suppressed Drift\n\n")
+		for _, sup := range s.Suppressed {
+			fmt.Fprintf(&b, "- `%s`", sup.Address)
+			if len(sup.Attributes) > 0 {
+				fmt.Fprintf(&b, " (`%s`)", strings.Join(sup.Attributes, "`, `"))
+			}
+			fmt.Fprintf(&b, ": %s%s\n", sup.Reason, expiresText(sup))
 		}
 		b.WriteString("\n")
-	} else {
+	}
+	if len(s.DriftedResources) == 0 && len(s.External.Resources) == 0 {
 		b.WriteString("_No drift detected._\n")
 	}
 	return b.String()
 }
 
-func RenderText(s plan.Stats, runner plan.RunnerKind) string {
+// MarkdownChanges renders a bullet per address, followed by the reason of a
+// replace or delete when known, with the attribute diff of the matching change
+// nested below it. Sensitive values are masked.
+func MarkdownChanges(addrs []string, changes []plan.Change) string {
+	var b strings.Builder
+	for _, addr := range addrs {
+		c, _ := plan.FindChange(changes, addr)
+		fmt.Fprintf(&b, "- `%s`%s\n", addr, markdownReason(c))
+		for _, a := range c.Attributes {
+			fmt.Fprintf(&b, "  - `%s`: `%s` → `%s`\n", a.Path, beforeText(a), afterText(a))
+		}
+	}
+	return b.String()
+}
+
+func RenderText(s plan.Stats, runner plan.RunnerVersion) string {
+	return fmt.Sprintf("Drift Summary (%s)\n", runner) + textBody(s)
+}
+
+func textBody(s plan.Stats) string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "Drift Summary (%s)\n", runner)
+	if v := PlanVersion(s); v != "" {
+		fmt.Fprintf(&b, "Plan format: %s\n", v)
+	}
 	fmt.Fprintf(&b, "Updates: %d\n", s.Updates)
 	fmt.Fprintf(&b, "Replaces: %d\n", s.Replaces)
+	if s.Replaces > 0 {
+		fmt.Fprintf(&b, "  Create before destroy: %d\n", s.CreateBeforeDestroy)
+		fmt.Fprintf(&b, "  Delete before create: %d\n", s.DeleteBeforeCreate)
+	}
 	fmt.Fprintf(&b, "Deletes: %d\n", s.Deletes)
 	fmt.Fprintf(&b, "Total changed resources: %d\n", len(s.DriftedResources))
+	fmt.Fprintf(&b, "External drift (changed outside IaC): %d\n", len(s.External.Resources))
+	if hasRefactors(s) {
+		b.WriteString(TextRefactorCounts(s.Moves, s.Imports, s.Forgets))
+	}
+	if hasDataSources(s) {
+		b.WriteString(TextDataSourceCounts(s))
+	}
+	if len(s.Outputs) > 0 {
+		fmt.Fprintf(&b, "Changed outputs: %d\n", len(s.Outputs))
+	}
 
 	if len(s.DriftedResources) > 0 {
 		b.WriteString("\nDrifted Resources:\n")
-		for _, addr := range s.DriftedResources {
-			fmt.Fprintf(&b, "- %s\n", addr)
+		b.WriteString(TextChanges(s.DriftedResources, s.Changes))
+	}
+	if len(s.External.Resources) > 0 {
+		b.WriteString("\nExternal Drift:\n")
+		b.WriteString(TextChanges(s.External.Resources, s.External.Changes))
+	}
+	if refactors := Refactors(s.Resources); len(refactors) > 0 {
+		b.WriteString("\nMoved, Imported and Forgotten Resources:\n")
+		b.WriteString(TextRefactors(refactors))
+	}
+	b.WriteString(textDataSources(s))
+	if len(s.Outputs) > 0 {
+		b.WriteString("\nChanged Outputs:\n")
+		b.WriteString(TextOutputs(s.Outputs))
+	}
+	if len(s.Suppressed) > 0 {
+		b.WriteString("\nSuppressed Drift:\n")
+		for _, sup := range s.Suppressed {
+			fmt.Fprintf(&b, "- %s", sup.Address)
+			if len(sup.Attributes) > 0 {
+				fmt.Fprintf(&b, " [%s]", strings.Join(sup.Attributes, ", "))
+			}
+			fmt.Fprintf(&b, ": %s%s\n", sup.Reason, expiresText(sup))
 		}
-	} else {
+	}
+	if len(s.DriftedResources) == 0 && len(s.External.Resources) == 0 {
 		b.WriteString("\nNo drift detected.\n")
 	}
 	return b.String()
 }
 
-// RenderJSON outputs the minimal, machine-readable summary.
-// Fields mirror Markdown/Text modes (total == number of drifted resources).
-// No extra data is included.
-func RenderJSON(s plan.Stats) (string, error) {
-	payload := struct {
-		Updates  int      `json:"updates"`
-		Replaces int      `json:"replaces"`
-		Deletes  int      `json:"deletes"`
-		Drifted  []string `json:"drifted"`
-		Total    int      `json:"total"`
-	}{
-		Updates:  s.Updates,
-		Replaces: s.Replaces,
-		Deletes:  s.Deletes,
-		Drifted:  s.DriftedResources,
-		Total:    len(s.DriftedResources),
-	}
-
-	b, err := json.Marshal(payload) // compact valid JSON (no extra whitespace)
+// PlanVersion describes the plan's format_version and terraform_version, or
+// returns "" when the plan carries no format_version.
+func PlanVersion(s plan.Stats) string {
+	switch {
+	case s.FormatVersion == "":
+		return ""
+	case s.TerraformVersion == "":
+		return s.FormatVersion
+	}
+	return fmt.Sprintf("%s (terraform_version %s)", s.FormatVersion, s.TerraformVersion)
+}
+
+func expiresText(sup plan.Suppressed) string {
+	if sup.Expires.IsZero() {
+		return ""
+	}
+	return " (expires " + sup.Expires.Format("2006-01-02") + ")"
+}
+
+// TextChanges is the plain-text counterpart of MarkdownChanges.
+func TextChanges(addrs []string, changes []plan.Change) string {
+	var b strings.Builder
+	for _, addr := range addrs {
+		c, _ := plan.FindChange(changes, addr)
+		fmt.Fprintf(&b, "- %s%s\n", addr, textReason(c))
+		for _, a := range c.Attributes {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", a.Path, beforeText(a), afterText(a))
+		}
+	}
+	return b.String()
+}
+
+// JSONAttribute is the JSON form of a plan.AttributeChange.
+type JSONAttribute struct {
+	Path      string `json:"path"`
+	Kind      string `json:"kind"`
+	Before    any    `json:"before"`
+	After     any    `json:"after"`
+	Unknown   bool   `json:"unknown,omitempty"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+// JSONChange is the JSON form of a plan.Change.
+type JSONChange struct {
+	Address         string          `json:"address"`
+	PreviousAddress string          `json:"previous_address,omitempty"`
+	Actions         []string        `json:"actions"`
+	ActionReason    string          `json:"action_reason,omitempty"`
+	ReplacePaths    []string        `json:"replace_paths,omitempty"`
+	Deposed         string          `json:"deposed,omitempty"`
+	Attributes      []JSONAttribute `json:"attributes,omitempty"`
+}
+
+type jsonExternal struct {
+	Updates             int          `json:"updates"`
+	Replaces            int          `json:"replaces"`
+	CreateBeforeDestroy int          `json:"create_before_destroy"`
+	DeleteBeforeCreate  int          `json:"delete_before_create"`
+	Deletes             int          `json:"deletes"`
+	Drifted             []string     `json:"drifted"`
+	Total               int          `json:"total"`
+	Changes             []JSONChange `json:"changes,omitempty"`
+}
+
+// JSONSuppressed is the JSON form of a plan.Suppressed entry.
+type JSONSuppressed struct {
+	Address    string   `json:"address"`
+	External   bool     `json:"external,omitempty"`
+	Attributes []string `json:"attributes,omitempty"`
+	Reason     string   `json:"reason"`
+	Expires    string   `json:"expires,omitempty"`
+}
+
+type jsonReport struct {
+	Runner           string       `json:"runner,omitempty"`
+	RunnerVersion    string       `json:"runner_version,omitempty"`
+	FormatVersion    string       `json:"format_version,omitempty"`
+	TerraformVersion string       `json:"terraform_version,omitempty"`
+	Options          *jsonOptions `json:"options,omitempty"`
+
+	Updates             int            `json:"updates"`
+	Replaces            int            `json:"replaces"`
+	CreateBeforeDestroy int            `json:"create_before_destroy"`
+	DeleteBeforeCreate  int            `json:"delete_before_create"`
+	Deletes             int            `json:"deletes"`
+	Moves               int            `json:"moves"`
+	Imports             int            `json:"imports"`
+	Forgets             int            `json:"forgets"`
+	Drifted             []string       `json:"drifted"`
+	Total               int            `json:"total"`
+	Changes             []JSONChange   `json:"changes,omitempty"`
+	Refactors           []JSONRefactor `json:"refactors,omitempty"`
+
+	ManagedResources int                `json:"managed_resources"`
+	DataSources      int                `json:"data_sources"`
+	DeferredReads    []JSONDeferredRead `json:"deferred_reads,omitempty"`
+	DataChanges      []JSONChange       `json:"data_changes,omitempty"` // with --data-sources
+	Outputs          []JSONOutput       `json:"outputs,omitempty"`      // with --include-outputs
+
+	External   jsonExternal     `json:"external"`
+	Suppressed []JSONSuppressed `json:"suppressed,omitempty"`
+}
+
+// RenderJSON outputs the machine-readable summary.
+// Counts mirror Markdown/Text modes (total == number of drifted resources);
+// "changes" carries the per-attribute diff of every drifted resource and
+// "external" the drift detected outside of IaC (resource_drift). Drift hidden
+// by exemptions is listed under "suppressed" and excluded from the counts.
+// The runner, its version and the plan's format_version/terraform_version are
+// included when known, and "options" records the runner options of the plan.
+func RenderJSON(s plan.Stats, runner plan.RunnerVersion, opts plan.PlanOptions) (string, error) {
+	r := newJSONReport(s)
+	r.Runner, r.RunnerVersion = string(runner.Kind), runner.Version
+	r.Options = newJSONOptions(opts)
+	b, err := json.Marshal(r) // compact valid JSON (no extra whitespace)
 	if err != nil {
 		return "", err
 	}
 	return string(b), nil
 }
+
+func newJSONReport(s plan.Stats) jsonReport {
+	return jsonReport{
+		FormatVersion:       s.FormatVersion,
+		TerraformVersion:    s.TerraformVersion,
+		Updates:             s.Updates,
+		Replaces:            s.Replaces,
+		CreateBeforeDestroy: s.CreateBeforeDestroy,
+		DeleteBeforeCreate:  s.DeleteBeforeCreate,
+		Deletes:             s.Deletes,
+		Moves:               s.Moves,
+		Imports:             s.Imports,
+		Forgets:             s.Forgets,
+		Drifted:             s.DriftedResources,
+		Total:               len(s.DriftedResources),
+		Changes:             JSONChanges(s.Changes),
+		Refactors:           JSONRefactors(Refactors(s.Resources)),
+		ManagedResources:    s.ManagedResources,
+		DataSources:         s.DataSources,
+		DeferredReads:       jsonDeferredReads(s.DeferredReads),
+		DataChanges:         JSONChanges(s.DataChanges),
+		Outputs:             JSONOutputs(s.Outputs),
+		External: jsonExternal{
+			Updates:             s.External.Updates,
+			Replaces:            s.External.Replaces,
+			CreateBeforeDestroy: s.External.CreateBeforeDestroy,
+			DeleteBeforeCreate:  s.External.DeleteBeforeCreate,
+			Deletes:             s.External.Deletes,
+			Drifted:             s.External.Resources,
+			Total:               len(s.External.Resources),
+			Changes:             JSONChanges(s.External.Changes),
+		},
+		Suppressed: jsonSuppressed(s.Suppressed),
+	}
+}
+
+// jsonOptions records the runner options a plan was created with. Only the
+// names of -var arguments are recorded (values may be secret), and the
+// environment is omitted entirely.
+type jsonOptions struct {
+	Workspace   string   `json:"workspace,omitempty"`
+	VarFiles    []string `json:"var_files,omitempty"`
+	Vars        []string `json:"vars,omitempty"`
+	Targets     []string `json:"targets,omitempty"`
+	Lock        bool     `json:"lock"`
+	LockTimeout string   `json:"lock_timeout,omitempty"`
+	Parallelism int      `json:"parallelism,omitempty"`
+}
+
+func newJSONOptions(o plan.PlanOptions) *jsonOptions {
+	jo := &jsonOptions{
+		Workspace:   o.Workspace,
+		VarFiles:    o.VarFiles,
+		Targets:     o.Targets,
+		Lock:        !o.NoLock,
+		Parallelism: o.Parallelism,
+	}
+	for _, v := range o.Vars {
+		name, _, _ := strings.Cut(v, "=")
+		jo.Vars = append(jo.Vars, name)
+	}
+	if o.LockTimeout > 0 {
+		jo.LockTimeout = o.LockTimeout.String()
+	}
+	return jo
+}
+
+func JSONChanges(changes []plan.Change) []JSONChange {
+	out := make([]JSONChange, 0, len(changes))
+	for _, c := range changes {
+		jc := JSONChange{Address: c.Address, Actions: c.Actions, ActionReason: c.ActionReason, ReplacePaths: c.ReplacePaths, Deposed: c.Deposed}
+		if c.Moved() {
+			jc.PreviousAddress = c.PreviousAddress
+		}
+		jc.Attributes = jsonAttributes(c.Attributes)
+		out = append(out, jc)
+	}
+	return out
+}
+
+// jsonAttributes converts attribute changes to JSON, masking sensitive values.
+func jsonAttributes(attrs []plan.AttributeChange) []JSONAttribute {
+	var out []JSONAttribute
+	for _, a := range attrs {
+		ja := JSONAttribute{
+			Path:      a.Path,
+			Kind:      string(a.Kind),
+			Before:    a.Before,
+			After:     a.After,
+			Unknown:   a.Unknown,
+			Sensitive: a.Sensitive,
+		}
+		if a.Sensitive {
+			ja.Before, ja.After = sensitivePlaceholder, sensitivePlaceholder
+		}
+		out = append(out, ja)
+	}
+	return out
+}
+
+func jsonSuppressed(sups []plan.Suppressed) []JSONSuppressed {
+	out := make([]JSONSuppressed, 0, len(sups))
+	for _, sup := range sups {
+		js := JSONSuppressed{
+			Address:    sup.Address,
+			External:   sup.External,
+			Attributes: sup.Attributes,
+			Reason:     sup.Reason,
+		}
+		if !sup.Expires.IsZero() {
+			js.Expires = sup.Expires.Format("2006-01-02")
+		}
+		out = append(out, js)
+	}
+	return out
+}
+
+func beforeText(a plan.AttributeChange) string {
+	if a.Sensitive {
+		return sensitivePlaceholder
+	}
+	return valueText(a.Before)
+}
+
+func afterText(a plan.AttributeChange) string {
+	switch {
+	case a.Sensitive:
+		return sensitivePlaceholder
+	case a.Unknown:
+		return unknownPlaceholder
+	}
+	return valueText(a.After)
+}
+
+// valueText renders an attribute value as compact JSON (null for absent values).
+func valueText(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
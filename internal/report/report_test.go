@@ -2,8 +2,11 @@ package report
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ha36d/drift-checker/internal/plan"
 )
@@ -17,7 +20,7 @@ func TestRenderMarkdownAndJSON(t *testing.T) {
 		TotalResources:   3,
 	}
 
-	md := RenderMarkdown(s, plan.RunnerTofu)
+	md := RenderMarkdown(s, plan.RunnerVersion{Kind: plan.RunnerTofu})
 	if !strings.Contains(md, "Drift Summary (tofu)") {
 		t.Fatalf("markdown missing runner header: %s", md)
 	}
@@ -27,7 +30,7 @@ func TestRenderMarkdownAndJSON(t *testing.T) {
 		}
 	}
 
-	js, err := RenderJSON(s)
+	js, err := RenderJSON(s, plan.RunnerVersion{}, plan.PlanOptions{})
 	if err != nil {
 		t.Fatalf("RenderJSON error: %v", err)
 	}
@@ -47,4 +50,138 @@ func TestRenderMarkdownAndJSON(t *testing.T) {
 	if len(payload.Drifted) != 3 {
 		t.Fatalf("expected 3 drifted, got %d", len(payload.Drifted))
 	}
-}
\ No newline at end of file
+}
+
+func TestRenderAttributeDiffs(t *testing.T) {
+	s := plan.Stats{
+		Updates:          1,
+		DriftedResources: []string{"aws_instance.web"},
+		Changes: []plan.Change{{
+			Address: "aws_instance.web",
+			Actions: []string{"update"},
+			Attributes: []plan.AttributeChange{
+				{Path: "instance_type", Kind: plan.DiffChanged, Before: "t3.micro", After: "t3.large"},
+				{Path: "user_data", Kind: plan.DiffChanged, Before: "s3cr3t", After: "n3w", Sensitive: true},
+			},
+		}},
+	}
+
+	md := RenderMarkdown(s, plan.RunnerVersion{Kind: plan.RunnerTofu})
+	if !strings.Contains(md, "`instance_type`: `\"t3.micro\"` → `\"t3.large\"`") {
+		t.Fatalf("markdown missing attribute diff:\n%s", md)
+	}
+	txt := RenderText(s, plan.RunnerVersion{Kind: plan.RunnerTofu})
+	if !strings.Contains(txt, `instance_type: "t3.micro" -> "t3.large"`) {
+		t.Fatalf("text missing attribute diff:\n%s", txt)
+	}
+	js, err := RenderJSON(s, plan.RunnerVersion{}, plan.PlanOptions{})
+	if err != nil {
+		t.Fatalf("RenderJSON error: %v", err)
+	}
+	for name, out := range map[string]string{"md": md, "text": txt, "json": js} {
+		if strings.Contains(out, "s3cr3t") || strings.Contains(out, "n3w") {
+			t.Fatalf("%s output leaked a sensitive value:\n%s", name, out)
+		}
+	}
+	if !strings.Contains(js, `"path":"instance_type"`) {
+		t.Fatalf("json missing attribute path: %s", js)
+	}
+}
+
+func TestRender_NestedSensitive(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("..", "plan", "testdata", "plan_nested_sensitive.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := plan.ParseStats(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{"md", "text", "json", "sarif", "junit"} {
+		out, err := Render(format, s, plan.RunnerVersion{}, plan.PlanOptions{}, "")
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for _, secret := range []string{"hunter2", "s3cr3t", "t0k3n"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s output leaked %q:\n%s", format, secret, out)
+			}
+		}
+	}
+	if md := RenderMarkdown(s, plan.RunnerVersion{}); !strings.Contains(md, "`block[0].name`: `null` → `\"primary\"`") {
+		t.Errorf("markdown lost the non-sensitive nested value:\n%s", md)
+	}
+}
+
+func TestRenderJSON_Options(t *testing.T) {
+	js, err := RenderJSON(plan.Stats{}, plan.RunnerVersion{Kind: plan.RunnerTofu, Version: "1.8.2"}, plan.PlanOptions{
+		VarFiles:    []string{"prod.tfvars"},
+		Vars:        []string{"db_password=hunter2"},
+		Targets:     []string{"module.db"},
+		NoLock:      true,
+		LockTimeout: 30 * time.Second,
+		Env:         []string{"AWS_SECRET_ACCESS_KEY=xyz"},
+	})
+	if err != nil {
+		t.Fatalf("RenderJSON error: %v", err)
+	}
+	if strings.Contains(js, "hunter2") || strings.Contains(js, "AWS_SECRET") {
+		t.Fatalf("variable values or environment leaked into the report: %s", js)
+	}
+	for _, want := range []string{
+		`"runner":"tofu"`, `"runner_version":"1.8.2"`,
+		`"options":{"var_files":["prod.tfvars"],"vars":["db_password"],"targets":["module.db"],"lock":false,"lock_timeout":"30s"}`,
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("JSON missing %s:\n%s", want, js)
+		}
+	}
+}
+
+func TestRenderReplaceOrder(t *testing.T) {
+	s := plan.Stats{
+		Replaces:            2,
+		CreateBeforeDestroy: 1,
+		DeleteBeforeCreate:  1,
+		DriftedResources:    []string{"aws_instance.web", "aws_db_instance.main"},
+		Changes: []plan.Change{
+			{Address: "aws_instance.web", Mode: "managed", Actions: []string{"create", "delete"}},
+			{Address: "aws_db_instance.main", Mode: "managed", Actions: []string{"delete", "create"}},
+		},
+	}
+
+	md := RenderMarkdown(s, plan.RunnerVersion{Kind: plan.RunnerTofu})
+	for _, want := range []string{"  - **Create before destroy**: 1\n", "  - **Delete before create**: 1\n"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q in:\n%s", want, md)
+		}
+	}
+	txt := RenderText(s, plan.RunnerVersion{Kind: plan.RunnerTofu})
+	for _, want := range []string{"  Create before destroy: 1\n", "  Delete before create: 1\n"} {
+		if !strings.Contains(txt, want) {
+			t.Errorf("text missing %q in:\n%s", want, txt)
+		}
+	}
+	js, err := RenderJSON(s, plan.RunnerVersion{}, plan.PlanOptions{})
+	if err != nil {
+		t.Fatalf("RenderJSON error: %v", err)
+	}
+	if !strings.Contains(js, `"create_before_destroy":1,"delete_before_create":1`) {
+		t.Errorf("JSON missing the replace ordering: %s", js)
+	}
+	sarif, err := RenderSARIF(s, "", plan.RunnerVersion{})
+	if err != nil {
+		t.Fatalf("RenderSARIF error: %v", err)
+	}
+	for _, want := range []string{`"replace_order":"create_before_destroy"`, `"replace_order":"delete_before_create"`} {
+		if !strings.Contains(sarif, want) {
+			t.Errorf("SARIF missing %s:\n%s", want, sarif)
+		}
+	}
+
+	clean := RenderMarkdown(plan.Stats{}, plan.RunnerVersion{})
+	if strings.Contains(clean, "Create before destroy") {
+		t.Errorf("ordering shown without replaces:\n%s", clean)
+	}
+}
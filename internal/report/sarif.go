@@ -0,0 +1,301 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "drift-checker"
+	toolURI      = "https://github.com/ha36d/drift-checker"
+)
+
+// SARIF levels.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// ruleDescriptions holds the short description of every stable rule id.
+var ruleDescriptions = map[string]string{
+	"drift/update":  "Resource drifted and would be updated",
+	"drift/delete":  "Resource drifted and no longer exists",
+	"drift/replace": "Resource drifted and would be replaced",
+	"gate/create":   "Resource would be created",
+	"gate/update":   "Resource would be updated",
+	"gate/delete":   "Resource would be deleted",
+	"gate/replace":  "Resource would be replaced",
+	"gate/read":     "Data source would be read",
+	"gate/forget":   "Resource would be removed from state without being destroyed",
+	"gate/no-op":    "Resource is unchanged",
+}
+
+// SARIFResult is a single finding about a resource change.
+type SARIFResult struct {
+	RuleID   string
+	Level    string
+	Message  string
+	Change   plan.Change
+	Location *plan.Location // declaring file relative to Root, when known
+	Root     string         // working directory of the component
+	// Properties are added to the result's properties, e.g. the component
+	// and runner of an aggregated report.
+	Properties map[string]string
+}
+
+// SARIFNotification is a tool execution notification, such as a component
+// that failed to scan.
+type SARIFNotification struct {
+	Level   string
+	Message string
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+	Results     []sarifResult     `json:"results"`
+	Properties  map[string]string `json:"properties,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+	Region struct {
+		StartLine int `json:"startLine"`
+	} `json:"region"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// RenderSARIF renders every drifted resource as a SARIF 2.1.0 result
+// (rule ids drift/update, drift/delete, drift/replace). root is the scanned
+// working directory, used to resolve the declaring .tf file and line. The
+// runner and plan versions are recorded as run properties.
+func RenderSARIF(s plan.Stats, root string, runner plan.RunnerVersion) (string, error) {
+	return BuildSARIF(DriftSARIFResults(s, root), VersionProperties(s, runner))
+}
+
+// VersionProperties describes the runner and plan versions as report
+// properties (SARIF run properties, JUnit suite properties). Unknown values
+// are omitted.
+func VersionProperties(s plan.Stats, runner plan.RunnerVersion) map[string]string {
+	props := map[string]string{}
+	for k, v := range map[string]string{
+		"runner":            string(runner.Kind),
+		"runner_version":    runner.Version,
+		"format_version":    s.FormatVersion,
+		"terraform_version": s.TerraformVersion,
+	} {
+		if v != "" {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// DriftSARIFResults returns one result per planned or external drift of s.
+// Replaces carry their ordering in the replace_order property.
+func DriftSARIFResults(s plan.Stats, root string) []SARIFResult {
+	var results []SARIFResult
+	add := func(changes []plan.Change, external bool) {
+		for _, c := range changes {
+			action := c.Action()
+			level := LevelError
+			if action == plan.ActionUpdate {
+				level = LevelWarning
+			}
+			msg := fmt.Sprintf("%s drifted (%s)", c.ObjectAddress(), action)
+			if external {
+				msg = fmt.Sprintf("%s was changed outside of IaC (%s)", c.ObjectAddress(), action)
+			}
+			r := NewSARIFResult("drift/"+string(action), level, msg+attributeSummary(c), c, s, root)
+			if action == plan.ActionReplace {
+				r.Properties = map[string]string{"replace_order": replaceOrder(c)}
+			}
+			results = append(results, r)
+		}
+	}
+	add(s.Changes, false)
+	add(s.External.Changes, true)
+	return results
+}
+
+// NewSARIFResult builds a result for c, resolving its declaring file below root.
+func NewSARIFResult(ruleID, level, msg string, c plan.Change, s plan.Stats, root string) SARIFResult {
+	r := SARIFResult{RuleID: ruleID, Level: level, Message: msg, Change: c, Root: root}
+	if l, ok := plan.Locate(root, s, c); ok {
+		r.Location = &l
+	}
+	return r
+}
+
+// BuildSARIF assembles a SARIF 2.1.0 log from results. Physical locations are
+// included for results whose declaring file is known; props become the run's
+// properties. Notifications are recorded in the run's invocation, which is
+// unsuccessful when any of them is an error.
+func BuildSARIF(results []SARIFResult, props map[string]string, notifications ...SARIFNotification) (string, error) {
+	ids := map[string]string{}
+	for _, r := range results {
+		if _, ok := ids[r.RuleID]; !ok {
+			ids[r.RuleID] = r.Level
+		}
+	}
+	ruleIDs := make([]string, 0, len(ids))
+	for id := range ids {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolURI,
+			Rules:          make([]sarifRule, 0, len(ruleIDs)),
+		}},
+		Results: make([]sarifResult, 0, len(results)),
+	}
+	if len(props) > 0 {
+		run.Properties = props
+	}
+	if len(notifications) > 0 {
+		inv := sarifInvocation{ExecutionSuccessful: true}
+		for _, n := range notifications {
+			inv.ToolExecutionNotifications = append(inv.ToolExecutionNotifications, sarifNotification{Level: n.Level, Message: sarifMessage{Text: n.Message}})
+			if n.Level == LevelError {
+				inv.ExecutionSuccessful = false
+			}
+		}
+		run.Invocations = []sarifInvocation{inv}
+	}
+	index := map[string]int{}
+	for i, id := range ruleIDs {
+		r := sarifRule{ID: id, ShortDescription: sarifMessage{Text: ruleDescriptions[id]}}
+		if r.ShortDescription.Text == "" {
+			r.ShortDescription.Text = id
+		}
+		r.DefaultConfiguration.Level = ids[id]
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, r)
+		index[id] = i
+	}
+
+	for _, r := range results {
+		loc := sarifLocation{LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: r.Change.Address, Kind: "resource"}}}
+		if r.Location != nil {
+			loc.PhysicalLocation = &sarifPhysicalLocation{}
+			loc.PhysicalLocation.ArtifactLocation.URI = artifactURI(r.Root, r.Location.File)
+			loc.PhysicalLocation.Region.StartLine = r.Location.Line
+		}
+		props := map[string]any{"address": r.Change.Address, "actions": r.Change.Actions}
+		for k, v := range r.Properties {
+			props[k] = v
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:     r.RuleID,
+			RuleIndex:  index[r.RuleID],
+			Level:      r.Level,
+			Message:    sarifMessage{Text: r.Message},
+			Locations:  []sarifLocation{loc},
+			Properties: props,
+		})
+	}
+
+	b, err := json.Marshal(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// artifactURI makes file (relative to root) relative to the current directory,
+// which is where code-scanning tools expect the repository root to be.
+func artifactURI(root, file string) string {
+	if root == "" || filepath.IsAbs(root) {
+		return file
+	}
+	return path.Join(filepath.ToSlash(root), file)
+}
+
+// replaceOrder names the ordering of the replace c.
+func replaceOrder(c plan.Change) string {
+	if c.CreateBeforeDestroy() {
+		return "create_before_destroy"
+	}
+	return "delete_before_create"
+}
+
+// attributeSummary lists the changed attribute paths (never their values).
+func attributeSummary(c plan.Change) string {
+	if len(c.Attributes) == 0 {
+		return ""
+	}
+	paths := make([]string, 0, len(c.Attributes))
+	for _, a := range c.Attributes {
+		paths = append(paths, a.Path)
+	}
+	return ": " + strings.Join(paths, ", ")
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ha36d/drift-checker/internal/plan"
+)
+
+func TestRenderSARIF(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.tf"), []byte("resource \"aws_instance\" \"web\" {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := plan.Stats{
+		Updates:          1,
+		Deletes:          1,
+		DriftedResources: []string{"aws_instance.web", "aws_s3_bucket.logs"},
+		Changes: []plan.Change{
+			{Address: "aws_instance.web", Mode: "managed", Type: "aws_instance", Name: "web", Actions: []string{"update"},
+				Attributes: []plan.AttributeChange{{Path: "password", Kind: plan.DiffChanged, Before: "s3cr3t", After: "n3w", Sensitive: true}}},
+			{Address: "aws_s3_bucket.logs", Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Actions: []string{"delete"}},
+		},
+	}
+
+	out, err := RenderSARIF(s, root, plan.RunnerVersion{})
+	if err != nil {
+		t.Fatalf("RenderSARIF error: %v", err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation *struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v\n%s", err, out)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF envelope: %s", out)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "drift/delete" || run.Tool.Driver.Rules[1].ID != "drift/update" {
+		t.Fatalf("unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(run.Results))
+	}
+	web := run.Results[0]
+	if web.RuleID != "drift/update" || web.Level != LevelWarning {
+		t.Fatalf("unexpected web result: %+v", web)
+	}
+	if pl := web.Locations[0].PhysicalLocation; pl == nil || pl.ArtifactLocation.URI != "main.tf" || pl.Region.StartLine != 1 {
+		t.Fatalf("expected main.tf:1 location, got %+v", pl)
+	}
+	if logs := run.Results[1]; logs.Level != LevelError || logs.Locations[0].PhysicalLocation != nil {
+		t.Fatalf("unexpected logs result: %+v", logs)
+	}
+	if strings.Contains(out, "s3cr3t") || strings.Contains(out, "n3w") {
+		t.Fatalf("SARIF leaked a sensitive value: %s", out)
+	}
+}
+
+func TestRuleDescriptions(t *testing.T) {
+	actions := []plan.Action{
+		plan.ActionCreate, plan.ActionUpdate, plan.ActionDelete, plan.ActionReplace,
+		plan.ActionRead, plan.ActionForget, plan.ActionNoOp,
+	}
+	for _, a := range actions {
+		if ruleDescriptions["gate/"+string(a)] == "" {
+			t.Errorf("no description for gate/%s", a)
+		}
+	}
+	for _, a := range []plan.Action{plan.ActionUpdate, plan.ActionDelete, plan.ActionReplace} {
+		if ruleDescriptions["drift/"+string(a)] == "" {
+			t.Errorf("no description for drift/%s", a)
+		}
+	}
+}